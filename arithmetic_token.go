@@ -277,7 +277,8 @@ func ArithmeticTokenize(str string) ([]*ArithmeticTokenType, error) {
 			status, values = InitArithmeticStatus(b, values)
 			break
 		case Identifier:
-			if IsArithmetic(b) {
+			if IsArithmetic(b) || b == '#' || (b == '-' && values[len(values)-1] == '[') {
+				// items.# items[-1]
 				values = append(values, b)
 			} else {
 				t := &ArithmeticTokenType{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/crossoverJie/xjson"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -145,8 +146,79 @@ func BenchmarkDecode(b *testing.B) {
 	}
 }
 
+var paths = []string{
+	"person.name.fullName",
+	"person.github.followers",
+	"person.gravatar.avatars[0].url",
+	"person.geo.lat",
+	"person.twitter.handle",
+}
+
+func BenchmarkGet(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, path := range paths {
+			xjson.Get(str, path)
+		}
+	}
+}
+
+func BenchmarkDocumentGet(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc, _ := xjson.ParseDocument(str)
+		doc.GetMany(paths...)
+	}
+}
+
 func TestBig(t *testing.T) {
 	decode, err := xjson.Decode(str)
 	assert.Nil(t, err)
 	fmt.Println(decode)
 }
+
+// largeEvent is an event about 50 KB, the fields we read are at the beginning and the end.
+var largeEvent = func() string {
+	var builder strings.Builder
+	builder.WriteString(`{"id":"e-1","type":"click","items":[`)
+	for i := 0; i < 400; i++ {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(fmt.Sprintf(`{"index":%d,"name":"item-%d","price":%d.5,"tags":["a","b","c"],"desc":"a long description of the item é"}`, i, i, i))
+	}
+	builder.WriteString(`],"user":{"name":"bob","age":20}}`)
+	return builder.String()
+}()
+
+func BenchmarkGetLarge(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		xjson.Get(largeEvent, "type")
+		xjson.Get(largeEvent, "user.name")
+	}
+}
+
+func BenchmarkDocumentGetLarge(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc, _ := xjson.ParseDocument(largeEvent)
+		doc.GetMany("type", "user.name")
+	}
+}
+
+func BenchmarkJsonDecodeLarge(b *testing.B) {
+	b.ReportAllocs()
+	data := []byte(largeEvent)
+	for i := 0; i < b.N; i++ {
+		var m map[string]interface{}
+		json.Unmarshal(data, &m)
+	}
+}
+
+func TestGetLarge(t *testing.T) {
+	fmt.Println(len(largeEvent))
+	assert.Equal(t, xjson.Get(largeEvent, "type").String(), "click")
+	assert.Equal(t, xjson.Get(largeEvent, "user.name").String(), "bob")
+	assert.Equal(t, xjson.Get(largeEvent, "items[399].index").Int(), 399)
+}
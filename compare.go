@@ -0,0 +1,170 @@
+package xjson
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// equalValues compare two values of the decoded tree, numbers are compared by value,
+// objects and arrays are compared member by member.
+func equalValues(x, y interface{}) bool {
+	if c, ok := compareNumbers(x, y); ok {
+		return c == 0
+	}
+	if isNumber(x) || isNumber(y) {
+		return false
+	}
+	switch xv := x.(type) {
+	case string:
+		yv, ok := y.(string)
+		return ok && xv == yv
+	case bool:
+		yv, ok := y.(bool)
+		return ok && xv == yv
+	case NullValue:
+		_, ok := y.(NullValue)
+		return ok
+	case *[]interface{}:
+		yv, ok := y.(*[]interface{})
+		if !ok || len(*xv) != len(*yv) {
+			return false
+		}
+		for i := range *xv {
+			if !equalValues((*xv)[i], (*yv)[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		yv, ok := y.(map[string]interface{})
+		if !ok || len(xv) != len(yv) {
+			return false
+		}
+		for k, v := range xv {
+			v2, ok := yv[k]
+			if !ok || !equalValues(v, v2) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compareValues order two values, only numbers and strings can be ordered.
+func compareValues(x, y interface{}) (int, bool) {
+	if c, ok := compareNumbers(x, y); ok {
+		return c, true
+	}
+	s1, ok1 := x.(string)
+	s2, ok2 := y.(string)
+	if ok1 && ok2 {
+		// UTF-8 的字节序和 Unicode 码点顺序一致
+		return strings.Compare(s1, s2), true
+	}
+	return 0, false
+}
+
+// compareNumbers compare two numbers exactly, ok is false when x or y is not a number.
+func compareNumbers(x, y interface{}) (int, bool) {
+	if !isNumber(x) || !isNumber(y) {
+		return 0, false
+	}
+	switch xv := x.(type) {
+	case int:
+		if yv, ok := y.(int); ok {
+			return compareInt(xv, yv), true
+		}
+	case float64:
+		if yv, ok := y.(float64); ok {
+			return compareFloat(xv, yv), true
+		}
+	}
+	// RawNumber、大数和混合的类型用 big.Float 比较，float64 会丢失精度
+	m, ok1 := bigNumber(x)
+	n, ok2 := bigNumber(y)
+	if !ok1 || !ok2 {
+		// NaN 和 Inf
+		a, _ := numberValue(x)
+		b, _ := numberValue(y)
+		return compareFloat(a, b), true
+	}
+	return m.Cmp(n), true
+}
+
+func isNumber(v interface{}) bool {
+	t := typeOfToken(v)
+	return t == Number || t == Float
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// numberPrec is the precision to compare the numbers, it is exact for the integers up to 300 digits.
+const numberPrec = 1024
+
+// bigNumber convert the number to big.Float, ok is false for NaN and Inf.
+func bigNumber(v interface{}) (*big.Float, bool) {
+	f := new(big.Float).SetPrec(numberPrec)
+	switch data := v.(type) {
+	case int:
+		return f.SetInt64(int64(data)), true
+	case int64:
+		return f.SetInt64(data), true
+	case uint64:
+		return f.SetUint64(data), true
+	case *big.Int:
+		return f.SetInt(data), true
+	case *big.Float:
+		return data, !data.IsInf()
+	case float64:
+		if math.IsNaN(data) || math.IsInf(data, 0) {
+			return nil, false
+		}
+		return f.SetFloat64(data), true
+	case RawNumber:
+		_, ok := f.SetString(string(data))
+		return f, ok && !f.IsInf()
+	}
+	return nil, false
+}
+
+// numberValue return v as float64 if it is a JSON number.
+func numberValue(v interface{}) (float64, bool) {
+	switch data := v.(type) {
+	case int:
+		return float64(data), true
+	case float64:
+		return data, true
+	case RawNumber:
+		f, err := data.Float64()
+		return f, err == nil
+	}
+	switch typeOfToken(v) {
+	case Number, Float:
+		f, err := strconv.ParseFloat(interface2String(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
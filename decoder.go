@@ -0,0 +1,421 @@
+package xjson
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// Delim is one of the JSON delimiters { } [ ] returned by Decoder.Token.
+type Delim rune
+
+func (d Delim) String() string {
+	return string(d)
+}
+
+// the position of the Decoder in the token stream, like encoding/json.
+const (
+	tokenTopValue = iota
+	tokenArrayStart
+	tokenArrayValue
+	tokenArrayComma
+	tokenObjectStart
+	tokenObjectKey
+	tokenObjectColon
+	tokenObjectValue
+	tokenObjectComma
+)
+
+// minReadSize is the min free space of the buffer for every read.
+const minReadSize = 4096
+
+// Decoder read and decode JSON values from a stream, only the value being decoded is kept in
+// the buffer, so a large array can be read element by element with Token, More and Decode.
+type Decoder struct {
+	r     io.Reader
+	buf   []byte
+	scanp int // the start of unread data in buf
+	// offset is the count of bytes discarded before buf
+	offset int
+	err    error
+	mode   NumberMode
+
+	tokenState int
+	tokenStack []int
+}
+
+// NewDecoder return a Decoder which read from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// UseNumberMode decide which Go type a number is decoded to by Token.
+func (d *Decoder) UseNumberMode(mode NumberMode) {
+	d.mode = mode
+}
+
+// InputOffset return the byte offset of the current position in the stream.
+func (d *Decoder) InputOffset() int {
+	return d.offset + d.scanp
+}
+
+// Decode read the next JSON value and store it in the value pointed to by v, like Unmarshal.
+func (d *Decoder) Decode(v interface{}) error {
+	if err := d.tokenPrepareForDecode(); err != nil {
+		return err
+	}
+	if !d.tokenValueAllowed() {
+		return d.syntaxError("unexpected value")
+	}
+	start, end, err := d.readValue()
+	if err != nil {
+		return err
+	}
+	base := d.offset + start
+	err = Unmarshal(string(d.buf[start:end]), v)
+	d.scanp = end
+	d.tokenValueEnd()
+	return relocate(err, base)
+}
+
+// More check if there is another element in the current array or object.
+func (d *Decoder) More() bool {
+	c, err := d.peek()
+	return err == nil && c != ']' && c != '}'
+}
+
+// Token return the next token in the stream, it is a Delim for { } [ ], string, number (by
+// the NumberMode), bool or JSONNull. The commas and colons are skipped, io.EOF is returned
+// at the end of stream.
+func (d *Decoder) Token() (interface{}, error) {
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case '[':
+			if !d.tokenValueAllowed() {
+				return d.tokenError(c)
+			}
+			d.scanp++
+			d.tokenStack = append(d.tokenStack, d.tokenState)
+			d.tokenState = tokenArrayStart
+			return Delim('['), nil
+		case ']':
+			if d.tokenState != tokenArrayStart && d.tokenState != tokenArrayComma {
+				return d.tokenError(c)
+			}
+			d.scanp++
+			d.popTokenState()
+			return Delim(']'), nil
+		case '{':
+			if !d.tokenValueAllowed() {
+				return d.tokenError(c)
+			}
+			d.scanp++
+			d.tokenStack = append(d.tokenStack, d.tokenState)
+			d.tokenState = tokenObjectStart
+			return Delim('{'), nil
+		case '}':
+			if d.tokenState != tokenObjectStart && d.tokenState != tokenObjectComma {
+				return d.tokenError(c)
+			}
+			d.scanp++
+			d.popTokenState()
+			return Delim('}'), nil
+		case ':':
+			if d.tokenState != tokenObjectColon {
+				return d.tokenError(c)
+			}
+			d.scanp++
+			d.tokenState = tokenObjectValue
+			continue
+		case ',':
+			if d.tokenState == tokenArrayComma {
+				d.scanp++
+				d.tokenState = tokenArrayValue
+				continue
+			}
+			if d.tokenState == tokenObjectComma {
+				d.scanp++
+				d.tokenState = tokenObjectKey
+				continue
+			}
+			return d.tokenError(c)
+		case '"':
+			if d.tokenState == tokenObjectStart || d.tokenState == tokenObjectKey {
+				v, err := d.decodeScalar()
+				if err != nil {
+					return nil, err
+				}
+				d.tokenState = tokenObjectColon
+				return v, nil
+			}
+			fallthrough
+		default:
+			if !d.tokenValueAllowed() {
+				return d.tokenError(c)
+			}
+			v, err := d.decodeScalar()
+			if err != nil {
+				return nil, err
+			}
+			d.tokenValueEnd()
+			return v, nil
+		}
+	}
+}
+
+// decodeScalar decode the string, number or literal at the current position.
+func (d *Decoder) decodeScalar() (interface{}, error) {
+	start, end, err := d.readValue()
+	if err != nil {
+		return nil, err
+	}
+	v, err := DecodeWithNumberMode(string(d.buf[start:end]), d.mode)
+	if err != nil {
+		return nil, relocate(err, d.offset+start)
+	}
+	d.scanp = end
+	switch v.(type) {
+	case map[string]interface{}, *[]interface{}:
+		return nil, d.syntaxError("unexpected value")
+	}
+	return v, nil
+}
+
+func (d *Decoder) popTokenState() {
+	d.tokenState = d.tokenStack[len(d.tokenStack)-1]
+	d.tokenStack = d.tokenStack[:len(d.tokenStack)-1]
+	d.tokenValueEnd()
+}
+
+// tokenPrepareForDecode skip the comma or colon before the value when Token is mixed with Decode.
+func (d *Decoder) tokenPrepareForDecode() error {
+	switch d.tokenState {
+	case tokenArrayComma:
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c != ',' {
+			return d.syntaxError("expected ',' after array element")
+		}
+		d.scanp++
+		d.tokenState = tokenArrayValue
+	case tokenObjectColon:
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c != ':' {
+			return d.syntaxError("expected ':' after object key")
+		}
+		d.scanp++
+		d.tokenState = tokenObjectValue
+	}
+	return nil
+}
+
+func (d *Decoder) tokenValueAllowed() bool {
+	switch d.tokenState {
+	case tokenTopValue, tokenArrayStart, tokenArrayValue, tokenObjectValue:
+		return true
+	}
+	return false
+}
+
+func (d *Decoder) tokenValueEnd() {
+	switch d.tokenState {
+	case tokenArrayStart, tokenArrayValue:
+		d.tokenState = tokenArrayComma
+	case tokenObjectValue:
+		d.tokenState = tokenObjectComma
+	}
+}
+
+func (d *Decoder) tokenError(c byte) (interface{}, error) {
+	return nil, d.syntaxError("unexpected '" + string(c) + "'")
+}
+
+func (d *Decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.InputOffset(), Token: "", msg: msg}
+}
+
+// relocate add the offset of the value in the stream to the SyntaxError.
+func relocate(err error, base int) error {
+	var e *SyntaxError
+	if errors.As(err, &e) {
+		if e.Offset < 0 {
+			e.Offset = 0
+		}
+		e.Offset += base
+		// 行列号只是相对于这个值的，不再准确
+		e.Line, e.Column = 0, 0
+	}
+	return err
+}
+
+// peek skip the whitespace and return the next byte without consuming it.
+func (d *Decoder) peek() (byte, error) {
+	for {
+		for i := d.scanp; i < len(d.buf); i++ {
+			if !isWhitespace(d.buf[i]) {
+				d.scanp = i
+				return d.buf[i], nil
+			}
+		}
+		d.scanp = len(d.buf)
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.refill()
+	}
+}
+
+// readValue read until a whole value is in the buffer and return its position,
+// the incomplete value at the end of stream is returned too and reported by the parser.
+func (d *Decoder) readValue() (int, int, error) {
+	if _, err := d.peek(); err != nil {
+		if err == io.EOF {
+			return 0, 0, io.EOF
+		}
+		return 0, 0, err
+	}
+	scanned := 0
+	state := valueScanner{}
+	for {
+		start := d.scanp
+		end, ok := state.scan(bytesString(d.buf[start:]), scanned)
+		if ok {
+			return start, start + end, nil
+		}
+		scanned = len(d.buf) - start
+		if d.err != nil {
+			if d.err == io.EOF {
+				return start, len(d.buf), nil
+			}
+			return 0, 0, d.err
+		}
+		d.refill()
+	}
+}
+
+// refill read more data, the consumed data is discarded so that the buffer keep bounded.
+func (d *Decoder) refill() {
+	if d.scanp > 0 {
+		d.offset += d.scanp
+		n := copy(d.buf, d.buf[d.scanp:])
+		d.buf = d.buf[:n]
+		d.scanp = 0
+	}
+	if cap(d.buf)-len(d.buf) < minReadSize {
+		buf := make([]byte, len(d.buf), 2*cap(d.buf)+minReadSize)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err != nil {
+		d.err = err
+	}
+}
+
+// valueScanner find the end of a JSON value, it only track strings and nesting and the value is not
+// validated. It is used by Decoder to read a whole value and by Get to skip the values.
+type valueScanner struct {
+	depth    int
+	inString bool
+	escape   bool
+}
+
+// scan continue scanning buf from the offset scanned, return the length of the value
+// and true when the value is complete.
+func (s *valueScanner) scan(buf string, scanned int) (int, bool) {
+	if len(buf) == 0 {
+		return 0, false
+	}
+	first := buf[0]
+	if first != '{' && first != '[' && first != '"' {
+		// number, true, false, null 以分隔符结束
+		for i := scanned; i < len(buf); i++ {
+			if isWhitespace(buf[i]) || isDelimiter(buf[i]) {
+				if i == 0 {
+					return 1, true
+				}
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	i := scanned
+	if s.inString {
+		// 上次扫描停在字符串里
+		start := i
+		if s.escape {
+			start++
+		}
+		end, escape := stringEnd(buf, start)
+		if end >= len(buf) {
+			s.escape = escape
+			return 0, false
+		}
+		s.inString, s.escape = false, false
+		i = end + 1
+		if s.depth == 0 {
+			return i, true
+		}
+	}
+	depth := s.depth
+	for ; i < len(buf); i++ {
+		switch buf[i] {
+		case '"':
+			end, escape := stringEnd(buf, i+1)
+			if end >= len(buf) {
+				s.depth, s.inString, s.escape = depth, true, escape
+				return 0, false
+			}
+			i = end
+			if depth == 0 {
+				return i + 1, true
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	s.depth = depth
+	return 0, false
+}
+
+// stringEnd return the position of the closing quote of the string from buf[i], it is len(buf)
+// when the string is not closed, escape is true when buf end with a backslash then.
+func stringEnd(buf string, i int) (end int, escape bool) {
+	for ; i < len(buf); i++ {
+		switch buf[i] {
+		case '"':
+			return i, false
+		case '\\':
+			i++
+		}
+	}
+	return len(buf), i > len(buf)
+}
+
+// bytesString return b as a string without copying, the string is only used before b is modified.
+func bytesString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func isDelimiter(b byte) bool {
+	switch b {
+	case ',', ':', '[', ']', '{', '}', '"':
+		return true
+	}
+	return false
+}
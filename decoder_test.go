@@ -0,0 +1,155 @@
+package xjson
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	str := `{"name":"bob","age":20} {"name":"alice","age":18}
+[1,2] "abc" 10 true null`
+	for _, r := range []io.Reader{strings.NewReader(str), iotest.OneByteReader(strings.NewReader(str))} {
+		d := NewDecoder(r)
+		var p Person
+		assert.Nil(t, d.Decode(&p))
+		assert.Equal(t, p.Name, "bob")
+		assert.Equal(t, p.Age, 20)
+		assert.Nil(t, d.Decode(&p))
+		assert.Equal(t, p.Name, "alice")
+		var arr []int
+		assert.Nil(t, d.Decode(&arr))
+		assert.Equal(t, arr, []int{1, 2})
+		var s string
+		assert.Nil(t, d.Decode(&s))
+		assert.Equal(t, s, "abc")
+		var v interface{}
+		assert.Nil(t, d.Decode(&v))
+		assert.Equal(t, v, 10)
+		assert.Nil(t, d.Decode(&v))
+		assert.Equal(t, v, true)
+		assert.Nil(t, d.Decode(&v))
+		assert.Nil(t, v)
+		assert.Equal(t, d.Decode(&v), io.EOF)
+	}
+
+	d := NewDecoder(strings.NewReader(`{"name":"bob"} {"name":1}`))
+	var p Person
+	assert.Nil(t, d.Decode(&p))
+	assert.NotNil(t, d.Decode(&p))
+
+	d = NewDecoder(strings.NewReader(`{"a":1}  {"a" 1}`))
+	var m map[string]int
+	assert.Nil(t, d.Decode(&m))
+	err := d.Decode(&m)
+	fmt.Println(err)
+	assert.Equal(t, err.(*SyntaxError).Offset, 14)
+
+	d = NewDecoder(strings.NewReader(`{"a":[1,2`))
+	assert.NotNil(t, d.Decode(&m))
+}
+
+func TestDecoder_Token(t *testing.T) {
+	str := `{"list":[{"id":1},{"id":2}],"ok":true,"n":null,"f":1.5,"s":"a\"b"}`
+	d := NewDecoder(iotest.OneByteReader(strings.NewReader(str)))
+	var tokens []interface{}
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		tokens = append(tokens, token)
+	}
+	fmt.Println(tokens)
+	assert.Equal(t, tokens, []interface{}{Delim('{'), "list", Delim('['), Delim('{'), "id", 1, Delim('}'),
+		Delim('{'), "id", 2, Delim('}'), Delim(']'), "ok", true, "n", JSONNull, "f", 1.5, "s", `a"b`, Delim('}')})
+
+	d = NewDecoder(strings.NewReader(`[1, 2`))
+	d.UseNumberMode(NumberModeRaw)
+	token, err := d.Token()
+	assert.Nil(t, err)
+	assert.Equal(t, token.(Delim).String(), "[")
+	token, _ = d.Token()
+	assert.Equal(t, token, RawNumber("1"))
+	token, _ = d.Token()
+	assert.Equal(t, token, RawNumber("2"))
+	_, err = d.Token()
+	assert.Equal(t, err, io.EOF)
+
+	for _, s := range []string{`]`, `{1:2}`, `[1 2]`, `{"a" "b"}`, `[}`, `{"a":1]`, `{"a":}`, `[-]`} {
+		d = NewDecoder(strings.NewReader(s))
+		var err error
+		for err == nil {
+			_, err = d.Token()
+		}
+		assert.NotEqual(t, err, io.EOF, s)
+	}
+}
+
+func TestDecoder_Stream(t *testing.T) {
+	// 逐个读取大数组的元素，缓冲区不会随着数组变大
+	var builder strings.Builder
+	builder.WriteString(`{"total":20000,"items":[`)
+	for i := 0; i < 20000; i++ {
+		if i > 0 {
+			builder.WriteString(",\n")
+		}
+		builder.WriteString(fmt.Sprintf(`{"name":"user%d","age":%d}`, i, i%100))
+	}
+	builder.WriteString(`]}`)
+	str := builder.String()
+
+	d := NewDecoder(strings.NewReader(str))
+	token, err := d.Token()
+	assert.Nil(t, err)
+	assert.Equal(t, token, Delim('{'))
+	token, _ = d.Token()
+	assert.Equal(t, token, "total")
+	var total int
+	assert.Nil(t, d.Decode(&total))
+	assert.Equal(t, total, 20000)
+	token, _ = d.Token()
+	assert.Equal(t, token, "items")
+	token, _ = d.Token()
+	assert.Equal(t, token, Delim('['))
+	count := 0
+	for d.More() {
+		var p Person
+		assert.Nil(t, d.Decode(&p))
+		assert.Equal(t, p.Name, fmt.Sprintf("user%d", count))
+		count++
+	}
+	assert.Equal(t, count, 20000)
+	token, _ = d.Token()
+	assert.Equal(t, token, Delim(']'))
+	token, _ = d.Token()
+	assert.Equal(t, token, Delim('}'))
+	assert.Equal(t, d.InputOffset(), len(str))
+	assert.True(t, cap(d.buf) < 64*1024)
+	_, err = d.Token()
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestValueScanner(t *testing.T) {
+	// 从任意位置继续扫描都要得到同样的结果
+	for _, value := range []string{`{"a":"x\"}y","b":[1,{"c":"\\"}]}`, `"a\\\"b"`, `[]`, `123`, `true`} {
+		str := value + ` ,`
+		for k := 0; k <= len(value); k++ {
+			var s valueScanner
+			n, ok := s.scan(str[:k], 0)
+			if !ok {
+				n, ok = s.scan(str, k)
+			}
+			assert.True(t, ok, value)
+			assert.Equal(t, n, len(value), value)
+		}
+		end, ok := skipValue(str, 0)
+		assert.True(t, ok)
+		assert.Equal(t, end, len(value))
+	}
+}
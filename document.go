@@ -0,0 +1,80 @@
+package xjson
+
+// Document is a decoded JSON, it can be queried many times without parsing again.
+type Document struct {
+	root interface{}
+}
+
+// ParseDocument decode json once and return a Document for querying.
+func ParseDocument(json string) (*Document, error) {
+	decode, err := Decode(json)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{root: decode}, nil
+}
+
+// Get is like xjson.Get, but reuse the decoded tree.
+func (d *Document) Get(grammar string) Result {
+	return getWithRoot(d.root, grammar)
+}
+
+// GetE is like xjson.GetE, but reuse the decoded tree.
+func (d *Document) GetE(grammar string) (Result, error) {
+	return getWithRootE(d.root, grammar)
+}
+
+// GetWithArithmetic is like xjson.GetWithArithmetic, but reuse the decoded tree.
+func (d *Document) GetWithArithmetic(grammar string) Result {
+	return getWithArithmeticRoot(d.root, grammar)
+}
+
+// GetMany query all the grammars, the results are in the same order.
+func (d *Document) GetMany(grammars ...string) []Result {
+	results := make([]Result, len(grammars))
+	for i, grammar := range grammars {
+		results[i] = d.Get(grammar)
+	}
+	return results
+}
+
+// JSONPath is like xjson.JSONPath, but reuse the decoded tree.
+func (d *Document) JSONPath(expr string) (Result, error) {
+	return jsonPathWithRoot(d.root, expr)
+}
+
+// GetPointer query the JSON Pointer, like xjson.GetPointer.
+func (d *Document) GetPointer(ptr string) Result {
+	r, err := getPointer(d.root, ptr)
+	if err != nil {
+		return buildEmptyResult()
+	}
+	return r
+}
+
+// SetPointer set value to the JSON Pointer, the missing member is added and
+// "-" append to the array, value can be any Go value which can be marshalled.
+func (d *Document) SetPointer(ptr string, value interface{}) error {
+	v, err := toTree(value)
+	if err != nil {
+		return err
+	}
+	root, err := setPointer(d.root, ptr, v, pointerSet)
+	if err != nil {
+		return err
+	}
+	d.root = root
+	return nil
+}
+
+// RemovePointer remove the target of the JSON Pointer.
+func (d *Document) RemovePointer(ptr string) error {
+	_, err := removePointer(d.root, ptr)
+	return err
+}
+
+// String return the document as JSON.
+func (d *Document) String() string {
+	data, _ := Marshal(d.root)
+	return string(data)
+}
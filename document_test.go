@@ -0,0 +1,28 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	str := `{"name":"bob","age":20,"people":[{"bob":{"age":10}},{"alice":{"age":10}}]}`
+	doc, err := ParseDocument(str)
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Get("name").String(), "bob")
+	assert.Equal(t, doc.Get("age").Int(), 20)
+	assert.Equal(t, doc.Get("people[1].alice.age").Int(), 10)
+	assert.Equal(t, doc.Get("abc").Exists(), false)
+	assert.Equal(t, doc.GetWithArithmetic("people[0].bob.age + people[1].alice.age").Int(), 20)
+
+	results := doc.GetMany("name", "age", "abc")
+	assert.Equal(t, len(results), 3)
+	assert.Equal(t, results[0].String(), "bob")
+	assert.Equal(t, results[1].Int(), 20)
+	assert.Equal(t, results[2].Exists(), false)
+
+	_, err = ParseDocument(`{"name":`)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+}
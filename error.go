@@ -0,0 +1,147 @@
+package xjson
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError describe a JSON syntax error and where it happened.
+type SyntaxError struct {
+	// Offset is the byte offset of the offending token, -1 means the end of input.
+	Offset int
+	// Line and Column start from 1, they are 0 when the input is unknown.
+	Line   int
+	Column int
+	// Token is the offending token.
+	Token string
+	// Expected is the tokens which are allowed at this position.
+	Expected []string
+	msg      string
+}
+
+func (e *SyntaxError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(e.msg)
+	if e.Line > 0 {
+		builder.WriteString(fmt.Sprintf(" at line %d, column %d", e.Line, e.Column))
+	} else if e.Offset >= 0 {
+		builder.WriteString(fmt.Sprintf(" at offset %d", e.Offset))
+	}
+	if len(e.Expected) > 0 {
+		builder.WriteString(", expected ")
+		builder.WriteString(strings.Join(e.Expected, " or "))
+	}
+	return builder.String()
+}
+
+// locate compute Line and Column from Offset.
+func (e *SyntaxError) locate(input string) {
+	if e.Offset < 0 || e.Offset > len(input) {
+		e.Offset = len(input)
+	}
+	e.Line, e.Column = 1, 1
+	for i := 0; i < e.Offset; i++ {
+		if input[i] == '\n' {
+			e.Line++
+			e.Column = 1
+		} else {
+			e.Column++
+		}
+	}
+}
+
+// newLexError build the SyntaxError of the character at str[i].
+func newLexError(str string, i int, msg string) *SyntaxError {
+	token := "EOF"
+	if i < len(str) {
+		r, _ := utf8.DecodeRuneInString(str[i:])
+		token = fmt.Sprintf("%q", r)
+		msg = msg + " " + token
+	}
+	e := &SyntaxError{
+		Offset: i,
+		Token:  token,
+		msg:    msg,
+	}
+	e.locate(str)
+	return e
+}
+
+// newParseError build the SyntaxError of the unexpected token, the expected tokens are derived from status.
+func newParseError(tokenType *TokenType, status status) *SyntaxError {
+	e := &SyntaxError{
+		Offset:   tokenType.Offset,
+		Token:    tokenDescription(tokenType),
+		Expected: expectedTokens(status),
+	}
+	if tokenType.T == EndJson {
+		e.Offset = -1
+		e.msg = "unexpected end of JSON input"
+	} else {
+		e.msg = "unexpected " + e.Token
+	}
+	return e
+}
+
+func tokenDescription(tokenType *TokenType) string {
+	switch tokenType.T {
+	case String:
+		return "string " + quoteString(tokenType.Value)
+	case Number, Float:
+		return "number " + tokenType.Value
+	case EndJson:
+		return "EOF"
+	default:
+		return "'" + tokenType.Value + "'"
+	}
+}
+
+var statusNames = []struct {
+	status status
+	name   string
+}{
+	{StatusEnd, "EOF"},
+	{StatusObjectKey, "object key"},
+	{StatusColon, "':'"},
+	{StatusObjectValue | StatusArrayValue | StatusRootValue, "value"},
+	{StatusComma, "','"},
+	{StatusBeginObject, "'{'"},
+	{StatusEndObject, "'}'"},
+	{StatusBeginArray, "'['"},
+	{StatusEndArray, "']'"},
+}
+
+func expectedTokens(s status) []string {
+	var expected []string
+	for _, n := range statusNames {
+		if includeTokenStatus(n.status, s) {
+			expected = append(expected, n.name)
+		}
+	}
+	return expected
+}
+
+// PathError describe which segment of the path failed and why.
+type PathError struct {
+	Path    string
+	Segment string
+	Reason  string
+	// syntax is true when the path itself is invalid
+	syntax bool
+}
+
+func (e *PathError) Error() string {
+	if e.Segment == "" {
+		return fmt.Sprintf("path '%s': %s", e.Path, e.Reason)
+	}
+	return fmt.Sprintf("path '%s': segment '%s' %s", e.Path, e.Segment, e.Reason)
+}
+
+func newPathError(path, segment, reason string) *PathError {
+	return &PathError{Path: path, Segment: segment, Reason: reason}
+}
+
+func newPathSyntaxError(path, segment, reason string) *PathError {
+	return &PathError{Path: path, Segment: segment, Reason: reason, syntax: true}
+}
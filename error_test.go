@@ -0,0 +1,62 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	_, err := Decode(`{"a":1 @@@ }`)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	e := err.(*SyntaxError)
+	assert.Equal(t, e.Offset, 7)
+	assert.Equal(t, e.Line, 1)
+	assert.Equal(t, e.Column, 8)
+	assert.Equal(t, e.Token, "'@'")
+
+	_, err = Decode(`{"a" x: 1}`)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	e = err.(*SyntaxError)
+	assert.Equal(t, e.Offset, 5)
+
+	_, err = Decode("{\n\"a\":1,\n\"b\" 2}")
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	e = err.(*SyntaxError)
+	assert.Equal(t, e.Line, 3)
+	assert.Equal(t, e.Column, 5)
+	assert.Equal(t, e.Token, "number 2")
+	assert.Equal(t, e.Expected, []string{"':'"})
+	assert.Equal(t, err.Error(), "unexpected number 2 at line 3, column 5, expected ':'")
+
+	_, err = Decode(`{"a":1,,}`)
+	assert.NotNil(t, err)
+	e = err.(*SyntaxError)
+	assert.Equal(t, e.Token, "','")
+	assert.Equal(t, e.Expected, []string{"object key"})
+
+	_, err = Decode(`[1 2]`)
+	assert.NotNil(t, err)
+	e = err.(*SyntaxError)
+	assert.Equal(t, e.Expected, []string{"','", "']'"})
+}
+
+func TestSyntaxErrorEOF(t *testing.T) {
+	for _, str := range []string{`{"a":1`, `{"a":"1`, `[1,2`, `{"a":tru`, `{}}`, `{} {}`, `{{}}`, `{"a":"\u0001` + "\x01" + `"}`} {
+		_, err := Decode(str)
+		assert.NotNil(t, err, str)
+		fmt.Println(err)
+	}
+
+	_, err := Decode(`{"a":1`)
+	e := err.(*SyntaxError)
+	assert.Equal(t, e.Offset, 6)
+	assert.Equal(t, e.Token, "EOF")
+	assert.Equal(t, err.Error(), "unexpected end of JSON input at line 1, column 7, expected ',' or '}'")
+
+	_, err = Decode(" \t\r\n{\"a\" : [ 1 , 2 ] }\n")
+	assert.Nil(t, err)
+}
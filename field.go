@@ -0,0 +1,161 @@
+package xjson
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField describe a struct field which can be resolved from/to JSON.
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// cachedStructFields return the JSON visible fields of t, the result is cached by type.
+func cachedStructFields(t reflect.Type) []structField {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]structField)
+	}
+	f, _ := fieldCache.LoadOrStore(t, structFields(t))
+	return f.([]structField)
+}
+
+// structFields walk t and its embedded structs, the shallower field win when names conflict.
+func structFields(t reflect.Type) []structField {
+	var (
+		fields    []structField
+		position  = map[string]int{}
+		ambiguous = map[string]bool{}
+		// visiting 记录正在遍历的类型，避免嵌入自身的结构体无限递归
+		visiting = map[reflect.Type]bool{}
+	)
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		visiting[t] = true
+		defer delete(visiting, t)
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+				// 匿名结构体的字段提升到外层
+				if !visiting[ft] {
+					walk(ft, appendIndex(index, i))
+				}
+				continue
+			}
+			if sf.PkgPath != "" {
+				// unexported
+				continue
+			}
+			name, opts := parseTag(tag)
+			field := structField{
+				name:      sf.Name,
+				index:     appendIndex(index, i),
+				tagged:    name != "",
+				omitEmpty: opts.contains("omitempty"),
+			}
+			if field.tagged {
+				field.name = name
+			}
+
+			pos, ok := position[field.name]
+			if !ok {
+				position[field.name] = len(fields)
+				fields = append(fields, field)
+				continue
+			}
+			// resolve conflict like encoding/json does
+			f := fields[pos]
+			if len(field.index) < len(f.index) || (len(field.index) == len(f.index) && field.tagged && !f.tagged) {
+				fields[pos] = field
+				ambiguous[field.name] = false
+			} else if len(field.index) == len(f.index) && field.tagged == f.tagged {
+				ambiguous[field.name] = true
+			}
+		}
+	}
+	walk(t, nil)
+
+	var result []structField
+	for _, f := range fields {
+		if !ambiguous[f.name] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func appendIndex(index []int, i int) []int {
+	r := make([]int, len(index)+1)
+	copy(r, index)
+	r[len(index)] = i
+	return r
+}
+
+// fieldByIndex like reflect.Value.FieldByIndex, alloc the nil embedded pointer when alloc is true.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// findField find field by name, fallback to case-insensitive matching.
+func findField(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	if i := strings.Index(tag, ","); i != -1 {
+		return tag[:i], tagOptions(tag[i+1:])
+	}
+	return tag, ""
+}
+
+func (o tagOptions) contains(name string) bool {
+	s := string(o)
+	for s != "" {
+		var next string
+		if i := strings.Index(s, ","); i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if s == name {
+			return true
+		}
+		s = next
+	}
+	return false
+}
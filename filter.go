@@ -0,0 +1,446 @@
+package xjson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// filterExpr is the parsed predicate of items[?(...)], it is evaluated for every element.
+type filterExpr interface {
+	eval(current interface{}) filterValue
+}
+
+// filterValue is the value of an operand, exists is false when the path doesn't match.
+type filterValue struct {
+	exists bool
+	object interface{}
+}
+
+func (v filterValue) truthy() bool {
+	if !v.exists {
+		return false
+	}
+	switch data := v.object.(type) {
+	case bool:
+		return data
+	case NullValue:
+		return false
+	default:
+		return true
+	}
+}
+
+// number return the operand as float64 if it is a JSON number.
+func (v filterValue) number() (float64, bool) {
+	if !v.exists {
+		return 0, false
+	}
+	return numberValue(v.object)
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e *literalExpr) eval(interface{}) filterValue {
+	return filterValue{exists: true, object: e.value}
+}
+
+// pathExpr is @.name or name, which is relative to the current element.
+type pathExpr struct {
+	path   string
+	tokens []*GrammarTokenType
+}
+
+func (e *pathExpr) eval(current interface{}) filterValue {
+	if len(e.tokens) == 0 {
+		return filterValue{exists: true, object: current}
+	}
+	r, err := walkPath(e.path, Result{Token: typeOfToken(current), object: current}, e.tokens, KeyStatus|BeginArrayIndexStatus)
+	if err != nil || !r.Exists() {
+		return filterValue{}
+	}
+	return filterValue{exists: true, object: r.object}
+}
+
+type notExpr struct {
+	x filterExpr
+}
+
+func (e *notExpr) eval(current interface{}) filterValue {
+	return filterValue{exists: true, object: !e.x.eval(current).truthy()}
+}
+
+type negExpr struct {
+	x filterExpr
+}
+
+func (e *negExpr) eval(current interface{}) filterValue {
+	n, ok := e.x.eval(current).number()
+	if !ok {
+		return filterValue{}
+	}
+	return filterValue{exists: true, object: -n}
+}
+
+type binaryExpr struct {
+	op   string
+	x, y filterExpr
+}
+
+func (e *binaryExpr) eval(current interface{}) filterValue {
+	switch e.op {
+	case "&&":
+		return filterValue{exists: true, object: e.x.eval(current).truthy() && e.y.eval(current).truthy()}
+	case "||":
+		return filterValue{exists: true, object: e.x.eval(current).truthy() || e.y.eval(current).truthy()}
+	}
+	x, y := e.x.eval(current), e.y.eval(current)
+	switch e.op {
+	case "==":
+		return filterValue{exists: true, object: equalValue(x, y)}
+	case "!=":
+		return filterValue{exists: true, object: !equalValue(x, y)}
+	case "<", "<=", ">", ">=":
+		c, ok := compareValue(x, y)
+		if !ok {
+			return filterValue{exists: true, object: false}
+		}
+		var b bool
+		switch e.op {
+		case "<":
+			b = c < 0
+		case "<=":
+			b = c <= 0
+		case ">":
+			b = c > 0
+		case ">=":
+			b = c >= 0
+		}
+		return filterValue{exists: true, object: b}
+	}
+
+	// + - * / %
+	a, ok1 := x.number()
+	b, ok2 := y.number()
+	if !ok1 || !ok2 {
+		if s1, ok := x.object.(string); ok && e.op == "+" {
+			if s2, ok := y.object.(string); ok {
+				return filterValue{exists: true, object: s1 + s2}
+			}
+		}
+		return filterValue{}
+	}
+	var n float64
+	switch e.op {
+	case "+":
+		n = a + b
+	case "-":
+		n = a - b
+	case "*":
+		n = a * b
+	case "/":
+		if b == 0 {
+			return filterValue{}
+		}
+		n = a / b
+	case "%":
+		if b == 0 {
+			return filterValue{}
+		}
+		n = math.Mod(a, b)
+	}
+	return filterValue{exists: true, object: n}
+}
+
+// equalValue compare the operands, the missing operand is only equal to another missing one.
+func equalValue(x, y filterValue) bool {
+	if !x.exists || !y.exists {
+		return x.exists == y.exists
+	}
+	return equalValues(x.object, y.object)
+}
+
+// compareValue only numbers and strings can be ordered.
+func compareValue(x, y filterValue) (int, bool) {
+	if !x.exists || !y.exists {
+		return 0, false
+	}
+	return compareValues(x.object, y.object)
+}
+
+// filterParser is a recursive descent parser of the predicate:
+//
+//	or      = and { "||" and }
+//	and     = not { "&&" not }
+//	not     = "!" not | compare
+//	compare = sum [ ("==" | "!=" | "<" | "<=" | ">" | ">=") sum ]
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/" | "%") unary }
+//	unary   = "-" unary | primary
+//	primary = "(" or ")" | string | number | true | false | null | path
+type filterParser struct {
+	str string
+	pos int
+}
+
+func parseFilter(str string) (filterExpr, error) {
+	p := &filterParser{str: str}
+	p.skipSpace()
+	if p.pos >= len(p.str) {
+		return nil, errors.New("empty filter")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.str) {
+		return nil, fmt.Errorf("unexpected '%s' in filter", p.str[p.pos:])
+	}
+	return expr, nil
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.str) && isWhitespace(p.str[p.pos]) {
+		p.pos++
+	}
+}
+
+// consume skip spaces and read op if the input starts with it.
+func (p *filterParser) consume(ops ...string) string {
+	p.skipSpace()
+	for _, op := range ops {
+		if strings.HasPrefix(p.str[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") != "" {
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: "||", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") != "" {
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: "&&", x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *filterParser) parseNot() (filterExpr, error) {
+	p.skipSpace()
+	if p.pos < len(p.str) && p.str[p.pos] == '!' && !strings.HasPrefix(p.str[p.pos:], "!=") {
+		p.pos++
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{x: x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *filterParser) parseCompare() (filterExpr, error) {
+	x, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	// <= 要在 < 之前匹配
+	op := p.consume("==", "!=", "<=", ">=", "<", ">")
+	if op == "" {
+		return x, nil
+	}
+	y, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryExpr{op: op, x: x, y: y}, nil
+}
+
+func (p *filterParser) parseSum() (filterExpr, error) {
+	x, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.consume("+", "-")
+		if op == "" {
+			return x, nil
+		}
+		y, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: op, x: x, y: y}
+	}
+}
+
+func (p *filterParser) parseProduct() (filterExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.consume("*", "/", "%")
+		if op == "" {
+			return x, nil
+		}
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: op, x: x, y: y}
+	}
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.consume("-") != "" {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negExpr{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterExpr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.str) {
+		return nil, errors.New("unexpected end of filter")
+	}
+	b := p.str[p.pos]
+	switch {
+	case b == '(':
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.consume(")") == "" {
+			return nil, errors.New("missing ) in filter")
+		}
+		return x, nil
+	case b == '"' || b == '\'':
+		return p.parseString(b)
+	case isDigit(b):
+		return p.parseNumber()
+	case b == '@' || isGrammarLetter(b):
+		return p.parsePath()
+	}
+	return nil, fmt.Errorf("unexpected '%c' in filter", b)
+}
+
+func (p *filterParser) parseString(quote byte) (filterExpr, error) {
+	var values []byte
+	for i := p.pos + 1; i < len(p.str); i++ {
+		b := p.str[i]
+		switch {
+		case b == quote:
+			p.pos = i + 1
+			return &literalExpr{value: string(values)}, nil
+		case b == '\\' && i+1 < len(p.str):
+			i++
+			if p.str[i] == '\'' {
+				values = append(values, '\'')
+				continue
+			}
+			// 转义序列最长是 \uXXXX\uXXXX
+			end := i + 11
+			if end > len(p.str) {
+				end = len(p.str)
+			}
+			v, n, err := unescape([]byte(p.str[i:end]), 0, values)
+			if err != nil {
+				return nil, fmt.Errorf("%s in filter", err)
+			}
+			values = v
+			i += n
+		default:
+			values = append(values, b)
+		}
+	}
+	return nil, errors.New("unterminated string in filter")
+}
+
+func (p *filterParser) parseNumber() (filterExpr, error) {
+	start := p.pos
+	for p.pos < len(p.str) && (isDigit(p.str[p.pos]) || p.str[p.pos] == '.') {
+		p.pos++
+	}
+	if p.pos < len(p.str) && (p.str[p.pos] == 'e' || p.str[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.str) && (p.str[p.pos] == '+' || p.str[p.pos] == '-') {
+			p.pos++
+		}
+		for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+			p.pos++
+		}
+	}
+	f, err := strconv.ParseFloat(p.str[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number '%s' in filter", p.str[start:p.pos])
+	}
+	return &literalExpr{value: f}, nil
+}
+
+// parsePath read @, @.name, @[0] or name until an operator.
+func (p *filterParser) parsePath() (filterExpr, error) {
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.str); p.pos++ {
+		b := p.str[p.pos]
+		if b == '[' {
+			depth++
+		} else if b == ']' {
+			depth--
+		} else if depth == 0 && !isGrammarLetter(b) && !isDigit(b) && b != '@' && b != '.' && b != '#' && b != '\\' {
+			// a.* 的 * 不是乘号
+			if !(b == '*' && p.pos > start && p.str[p.pos-1] == '.') {
+				break
+			}
+		}
+	}
+	path := p.str[start:p.pos]
+	switch path {
+	case "true":
+		return &literalExpr{value: true}, nil
+	case "false":
+		return &literalExpr{value: false}, nil
+	case "null":
+		return &literalExpr{value: JSONNull}, nil
+	}
+	if strings.HasPrefix(path, "@") {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "@"), ".")
+	}
+	tokens, err := GrammarTokenize(path)
+	if err != nil {
+		return nil, err
+	}
+	return &pathExpr{path: path, tokens: tokens}, nil
+}
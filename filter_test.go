@@ -0,0 +1,90 @@
+package xjson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFilter(t *testing.T) {
+	str := `{"people":[{"name":"bob","age":10,"tags":["a"]},{"name":"alice","age":20,"email":"a@b.c"},{"name":"tom","age":30.5,"active":false}],
+"items":[{"id":1,"status":"active"},{"id":2,"status":"closed"},{"id":3,"status":"active"}]}`
+	adults := Get(str, "people[?(@.age > 18)].name")
+	fmt.Println(adults)
+	assert.Equal(t, adults.Token, Token(ArrayObject))
+	assert.Equal(t, adults.Array(), []interface{}{"alice", "tom"})
+	assert.Equal(t, Get(str, `items[?status=="active"].id`).Array(), []interface{}{1, 3})
+	assert.Equal(t, Get(str, `items[?(@.status != "active")].id`).Array(), []interface{}{2})
+	assert.Equal(t, Get(str, `items[?(@.status == 'active')]`).Get("#").Int(), 2)
+
+	assert.Equal(t, Get(str, "people[?(@.age >= 20 && @.age < 30)].name").Array(), []interface{}{"alice"})
+	assert.Equal(t, Get(str, "people[?(@.age < 15 || @.name == \"tom\")].name").Array(), []interface{}{"bob", "tom"})
+	assert.Equal(t, Get(str, "people[?(@.age * 2 > 30)].name").Array(), []interface{}{"alice", "tom"})
+	assert.Equal(t, Get(str, "people[?(@.age == 30.5)].name").Array(), []interface{}{"tom"})
+	assert.Equal(t, Get(str, "people[?(@.email)].name").Array(), []interface{}{"alice"})
+	assert.Equal(t, Get(str, "people[?(!@.email)].name").Array(), []interface{}{"bob", "tom"})
+	assert.Equal(t, Get(str, "people[?(@.active == false)].name").Array(), []interface{}{"tom"})
+	assert.Equal(t, Get(str, `people[?(@.tags[0] == "a")].name`).Array(), []interface{}{"bob"})
+	assert.Equal(t, Get(str, `people[?(@.name > "b")].name`).Array(), []interface{}{"bob", "tom"})
+	assert.Equal(t, Get(str, "people[?(@.age > 100)].name").String(), `[]`)
+	assert.Equal(t, Get(`[1,5,10]`, "[?(@ > 3)]").Array(), []interface{}{5, 10})
+	assert.Equal(t, Get(`{"a":{"v":1},"b":{"v":2}}`, "[?(@.v > 1)]").String(), `[{"v":2}]`)
+	assert.Equal(t, Get(`[{"a":"x]"}]`, `[?(@.a == "x]")].a`).Array(), []interface{}{"x]"})
+
+	_, err := GetE(str, "people[?(@.age > )].name")
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	_, err = GetE(str, "people[?(@.age > 1].name")
+	assert.NotNil(t, err)
+	_, err = GetE(str, "people[?(@.age > 1)")
+	assert.NotNil(t, err)
+	_, err = GetE(str, "people[0].name[?(@ > 1)]")
+	assert.NotNil(t, err)
+}
+
+func TestParseFilter(t *testing.T) {
+	expr, err := parseFilter(`(@.a + 1) * 2 == 6 && !(@.b)`)
+	assert.Nil(t, err)
+	m := map[string]interface{}{"a": 2}
+	assert.Equal(t, expr.eval(m).truthy(), true)
+	m["b"] = true
+	assert.Equal(t, expr.eval(m).truthy(), false)
+
+	_, err = parseFilter(``)
+	assert.NotNil(t, err)
+	_, err = parseFilter(`@.a == "abc`)
+	assert.NotNil(t, err)
+}
+
+func TestParseFilterEscape(t *testing.T) {
+	m := map[string]interface{}{"a": "aA\r/\"'😀"}
+	for _, str := range []string{`@.a == "a\u0041\r\/\"'\ud83d\ude00"`, `@.a == 'aA\r/"\'😀'`} {
+		expr, err := parseFilter(str)
+		assert.Nil(t, err, str)
+		assert.Equal(t, expr.eval(m).truthy(), true, str)
+	}
+	expr, err := parseFilter(`@.a == "a\u0041"`)
+	assert.Nil(t, err)
+	assert.Equal(t, expr.eval(map[string]interface{}{"a": "au0041"}).truthy(), false)
+
+	_, err = parseFilter(`@.a == "\x"`)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	_, err = parseFilter(`@.a == "\u00"`)
+	assert.NotNil(t, err)
+}
+
+func TestGetFilterEqualObject(t *testing.T) {
+	str := `{"items":[
+{"id":1,"a":{"x":1,"y":"s","z":[1,{"p":true,"q":null}]},"b":{"z":[1,{"q":null,"p":true}],"y":"s","x":1.0}},
+{"id":2,"a":{"x":1,"y":"s"},"b":{"y":"s","x":2}},
+{"id":3,"a":[{"m":1,"n":2}],"b":[{"n":2,"m":1}]}]}`
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, Get(str, "items[?(@.a == @.b)].id").Array(), []interface{}{1, 3})
+		assert.Equal(t, Get(str, "items[?(@.a != @.b)].id").Array(), []interface{}{2})
+	}
+	r, err := JSONPath(str, "$.items[?@.a == @.b].id")
+	assert.Nil(t, err)
+	assert.Equal(t, r.Array(), []interface{}{1, 3})
+}
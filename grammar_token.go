@@ -1,6 +1,9 @@
 package xjson
 
-import "errors"
+import (
+	"errors"
+	"unicode/utf8"
+)
 
 type GrammarToken string
 
@@ -12,7 +15,17 @@ const (
 	BeginArrayIndex GrammarToken = "BeginArrayIndex"
 	EndArrayIndex   GrammarToken = "EndArrayIndex"
 	ArrayIndex      GrammarToken = "ArrayIndex"
-	EOF             GrammarToken = "EOF"
+	// Length is the # operator, items.# return the length of items.
+	Length GrammarToken = "Length"
+	// Wildcard is the * operator, obj.* return all values of obj.
+	Wildcard GrammarToken = "Wildcard"
+	// Descent is the .. operator, store..price return all price beneath store.
+	Descent GrammarToken = "Descent"
+	// QuotedKey is 'key with spaces' or ["first-name"], the value is a Key token.
+	QuotedKey GrammarToken = "QuotedKey"
+	// Filter is the predicate of items[?(@.age > 18)], the value is the expression after '?'.
+	Filter GrammarToken = "Filter"
+	EOF    GrammarToken = "EOF"
 )
 
 type GrammarStatus int
@@ -23,6 +36,8 @@ const (
 	BeginArrayIndexStatus GrammarStatus = 0x0010
 	EndArrayIndexStatus   GrammarStatus = 0x0020
 	ArrayIndexStatus      GrammarStatus = 0x0040
+	// EndStatus only EOF is allowed
+	EndStatus GrammarStatus = 0x0080
 )
 
 type GrammarTokenType struct {
@@ -35,6 +50,8 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 	var result []*GrammarTokenType
 	var values []byte
 	status := GrammarInit
+	// bracketKey is true when the quoted key is in brackets: ["first-name"]
+	bracketKey := false
 	for i := 0; i < len(str); i++ {
 		b := str[i]
 
@@ -118,6 +135,12 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 			break
 
 		case Dot:
+			if b == '.' && len(values) == 1 {
+				// ..id
+				values = append(values, b)
+				status = Descent
+				break
+			}
 			t := &GrammarTokenType{
 				T:     Dot,
 				Value: string(values),
@@ -126,9 +149,53 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 			values = nil
 			status, values = InitGrammarStatus(b, values)
 			break
+		case Length, Wildcard, Descent:
+			t := &GrammarTokenType{
+				T:     status,
+				Value: string(values),
+			}
+			result = append(result, t)
+			values = nil
+			status, values = InitGrammarStatus(b, values)
 
+		case BeginArrayIndex:
+			t := &GrammarTokenType{
+				T:     BeginArrayIndex,
+				Value: string(values),
+			}
+			result = append(result, t)
+			values = nil
+			status = ArrayIndex
+			// 当前字符是下标的第一个字符
+			fallthrough
 		case ArrayIndex:
-			if isDigit(b) {
+			if (b == '"' || b == '\'') && len(values) == 0 {
+				// ["first-name"]
+				values = append(values, b)
+				status = QuotedKey
+				bracketKey = true
+			} else if b == '?' && len(values) == 0 {
+				// items[?(@.age > 18)]
+				end, err := filterEnd(str, i+1)
+				if err != nil {
+					return nil, err
+				}
+				t := &GrammarTokenType{
+					T:     Filter,
+					Value: str[i+1 : end],
+				}
+				result = append(result, t)
+				values = []byte{']'}
+				status = EndArrayIndex
+				i = end
+			} else if isDigit(b) || ((b == '-' || b == '*') && len(values) == 0) {
+				// items[-1] 倒数第一个
+				values = append(values, b)
+			} else if b == ':' && (len(values) == 0 || values[0] != '*') {
+				// items[1:4] items[::2]
+				values = append(values, b)
+			} else if b == '-' && values[len(values)-1] == ':' {
+				// items[-2:-1]
 				values = append(values, b)
 			} else if b == ']' {
 				t := &GrammarTokenType{
@@ -144,6 +211,33 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 				return nil, errors.New("invalid array index ]")
 			}
 
+		case QuotedKey:
+			if b == '\\' && i+1 < len(str) {
+				i++
+				values = append(values, str[i])
+				break
+			}
+			if b != values[0] {
+				values = append(values, b)
+				break
+			}
+			t := &GrammarTokenType{
+				T:     Key,
+				Value: string(values[1:]),
+			}
+			result = append(result, t)
+			values = nil
+			status = GrammarInit
+			if bracketKey {
+				if i+1 >= len(str) || str[i+1] != ']' {
+					return nil, errors.New("invalid key, missing ]")
+				}
+				i++
+				values = append(values, ']')
+				status = EndArrayIndex
+				bracketKey = false
+			}
+
 		case EndArrayIndex:
 			t := &GrammarTokenType{
 				T:     EndArrayIndex,
@@ -156,6 +250,9 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 		}
 	}
 
+	if status == QuotedKey {
+		return nil, errors.New("unterminated quoted key")
+	}
 	if len(values) > 0 {
 		t := &GrammarTokenType{
 			T:     status,
@@ -167,6 +264,36 @@ func GrammarTokenize(str string) ([]*GrammarTokenType, error) {
 	return result, nil
 }
 
+// filterEnd return the index of the ']' which close the filter begin at str[i],
+// brackets and quoted strings inside the filter are skipped.
+func filterEnd(str string, i int) (int, error) {
+	depth := 0
+	for ; i < len(str); i++ {
+		switch str[i] {
+		case '"', '\'':
+			quote := str[i]
+			for i++; i < len(str) && str[i] != quote; i++ {
+				if str[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(str) {
+				return 0, errors.New("unterminated string in filter")
+			}
+		case '[', '(':
+			depth++
+		case ')':
+			depth--
+		case ']':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		}
+	}
+	return 0, errors.New("unterminated filter, missing ]")
+}
+
 func InitGrammarStatus(b byte, values []byte) (GrammarToken, []byte) {
 	if b == '\\' {
 		return GrammarEscape, values
@@ -179,6 +306,24 @@ func InitGrammarStatus(b byte, values []byte) (GrammarToken, []byte) {
 		values = append(values, b)
 		return Dot, values
 	}
+	if b == '#' {
+		values = append(values, b)
+		return Length, values
+	}
+	if b == '*' {
+		values = append(values, b)
+		return Wildcard, values
+	}
+	if b == '"' || b == '\'' {
+		// 'key with spaces'
+		values = append(values, b)
+		return QuotedKey, values
+	}
+	if b == '[' {
+		// [0].name or a[0][1]
+		values = append(values, b)
+		return BeginArrayIndex, values
+	}
 
 	return GrammarInit, values
 }
@@ -187,7 +332,8 @@ func isGrammarLetter(b byte) bool {
 	if b == '[' || b == ']' {
 		return false
 	}
-	return b >= 65 && b <= 122
+	// UTF-8 编码的非 ASCII 字符都是字母
+	return (b >= 65 && b <= 122) || b >= utf8.RuneSelf
 }
 
 type GrammarTokenReader struct {
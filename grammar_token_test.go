@@ -128,3 +128,114 @@ func TestGrammarTokenize(t *testing.T) {
 		fmt.Printf("%s  %s\n", tokenType.T, tokenType.Value)
 	}
 }
+
+func TestGrammarTokenArrayRoot(t *testing.T) {
+	tokenize, err := GrammarTokenize("[0].name")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[0].T, BeginArrayIndex)
+	assert.Equal(t, tokenize[1].T, ArrayIndex)
+	assert.Equal(t, tokenize[1].Value, "0")
+	assert.Equal(t, tokenize[2].T, EndArrayIndex)
+	assert.Equal(t, tokenize[3].T, Dot)
+	assert.Equal(t, tokenize[4].T, Key)
+	assert.Equal(t, tokenize[4].Value, "name")
+
+	tokenize, err = GrammarTokenize("a[0][12]")
+	assert.Nil(t, err)
+	assert.Equal(t, len(tokenize), 7)
+	assert.Equal(t, tokenize[5].T, ArrayIndex)
+	assert.Equal(t, tokenize[5].Value, "12")
+}
+
+func TestGrammarTokenNegativeIndex(t *testing.T) {
+	tokenize, err := GrammarTokenize("items[-1].#")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[2].T, ArrayIndex)
+	assert.Equal(t, tokenize[2].Value, "-1")
+	assert.Equal(t, tokenize[4].T, Dot)
+	assert.Equal(t, tokenize[5].T, Length)
+	assert.Equal(t, tokenize[5].Value, "#")
+
+	_, err = GrammarTokenize("items[1-]")
+	assert.NotNil(t, err)
+	_, err = GrammarTokenize("items[--1]")
+	assert.NotNil(t, err)
+}
+
+func TestGrammarTokenWildcard(t *testing.T) {
+	tokenize, err := GrammarTokenize("people[*].name.*")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[2].T, ArrayIndex)
+	assert.Equal(t, tokenize[2].Value, "*")
+	assert.Equal(t, tokenize[7].T, Wildcard)
+	assert.Equal(t, tokenize[7].Value, "*")
+}
+
+func TestGrammarTokenFilter(t *testing.T) {
+	tokenize, err := GrammarTokenize(`people[?(@.tags[0] == "a]")].name`)
+	assert.Nil(t, err)
+	for _, tokenType := range tokenize {
+		fmt.Println(tokenType.T, " ", tokenType.Value)
+	}
+	assert.Equal(t, tokenize[2].T, Filter)
+	assert.Equal(t, tokenize[2].Value, `(@.tags[0] == "a]")`)
+	assert.Equal(t, tokenize[3].T, EndArrayIndex)
+	assert.Equal(t, tokenize[5].Value, "name")
+
+	tokenize, err = GrammarTokenize(`items[?status=="active"]`)
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[2].Value, `status=="active"`)
+	assert.Equal(t, tokenize[3].T, EndArrayIndex)
+
+	_, err = GrammarTokenize(`items[?(@.a == "b)]`)
+	assert.NotNil(t, err)
+	_, err = GrammarTokenize(`items[?(@.a == 1)`)
+	assert.NotNil(t, err)
+}
+
+func TestGrammarTokenSlice(t *testing.T) {
+	tokenize, err := GrammarTokenize("items[-2:].name")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[2].T, ArrayIndex)
+	assert.Equal(t, tokenize[2].Value, "-2:")
+	tokenize, err = GrammarTokenize("items[1:-1:2]")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[2].Value, "1:-1:2")
+	_, err = GrammarTokenize("items[*:1]")
+	assert.NotNil(t, err)
+}
+
+func TestGrammarTokenDescent(t *testing.T) {
+	tokenize, err := GrammarTokenize("store..price")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[1].T, Descent)
+	assert.Equal(t, tokenize[1].Value, "..")
+	assert.Equal(t, tokenize[2].Value, "price")
+	tokenize, err = GrammarTokenize("..id")
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[0].T, Descent)
+	assert.Equal(t, tokenize[1].Value, "id")
+}
+
+func TestGrammarTokenQuotedKey(t *testing.T) {
+	tokenize, err := GrammarTokenize(`people[0]["first-name"].'e mail'.名字`)
+	assert.Nil(t, err)
+	for _, tokenType := range tokenize {
+		fmt.Println(tokenType.T, " ", tokenType.Value)
+	}
+	assert.Equal(t, tokenize[4].T, BeginArrayIndex)
+	assert.Equal(t, tokenize[5].T, Key)
+	assert.Equal(t, tokenize[5].Value, "first-name")
+	assert.Equal(t, tokenize[6].T, EndArrayIndex)
+	assert.Equal(t, tokenize[8].Value, "e mail")
+	assert.Equal(t, tokenize[10].Value, "名字")
+
+	tokenize, err = GrammarTokenize(`'a\'b\\c'`)
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[0].Value, `a'b\c`)
+
+	_, err = GrammarTokenize(`["a"`)
+	assert.NotNil(t, err)
+	_, err = GrammarTokenize(`'a`)
+	assert.NotNil(t, err)
+}
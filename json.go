@@ -1,9 +1,11 @@
 package xjson
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/crossoverJie/gscript"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -14,128 +16,80 @@ const (
 	ArrayObject       = "ArrayObject"
 )
 
+// NullValue is the type of JSON null in the decoded tree.
+type NullValue struct{}
+
+// JSONNull is the sentinel which JSON null is decoded to, it is different from "".
+var JSONNull = NullValue{}
+
+func (n NullValue) String() string {
+	return "null"
+}
+
+// MarshalJSON write null.
+func (n NullValue) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
 type Result struct {
 	Token  Token
 	object interface{}
 }
 
 func Decode(input string) (interface{}, error) {
-	tokenize, err := Tokenize(input)
-	if err != nil {
-		return nil, err
-	}
-	if len(tokenize) == 0 {
+	return DecodeWithNumberMode(input, NumberModeDefault)
+}
+
+// DecodeWithNumberMode is like Decode but decode numbers by mode.
+func DecodeWithNumberMode(input string, mode NumberMode) (interface{}, error) {
+	if isBlank(input) {
 		return nil, errors.New("input is empty")
 	}
-	reader := NewTokenReader(tokenize)
-	return Parse(reader)
+	decode, err := parse(NewLexerString(input), mode)
+	if e, ok := err.(*SyntaxError); ok {
+		e.locate(input)
+	}
+	return decode, err
 }
 
+// Get query grammar from json, the path which only has keys and indexes is scanned without decoding
+// the whole json, the json outside the target is not checked then, use GetE to check it.
 func Get(json, grammar string) Result {
+	// a.b[0].c 不需要构建整棵树
+	if result, ok := getLazy(json, grammar); ok {
+		return result
+	}
 	decode, err := Decode(json)
 	if err != nil {
 		return buildEmptyResult()
 	}
 
-	root, ok := decode.(map[string]interface{})
-	if !ok {
-		return buildEmptyResult()
-	}
-
-	return getWithRoot(root, grammar)
+	return getWithRoot(decode, grammar)
 
 }
 
-func getWithRoot(root map[string]interface{}, grammar string) Result {
-	tokenize, err := GrammarTokenize(grammar)
+// GetE is like Get, but return the error which explain why the query failed.
+func GetE(json, grammar string) (Result, error) {
+	decode, err := Decode(json)
 	if err != nil {
-		return buildEmptyResult()
-	}
-	reader := NewGrammarTokenReader(tokenize)
-	status := KeyStatus
-	result := Result{
-		Token:  JSONObject,
-		object: root,
-	}
-	for {
-		read := reader.Read()
-		switch read.T {
-		case Key:
-			if !includeGrammarTokenStatus(KeyStatus, status) {
-				return buildEmptyResult()
-			}
-			var (
-				m     map[string]interface{}
-				v     interface{}
-				token Token
-				ok    bool
-			)
-			switch result.object.(type) {
-			case map[string]interface{}:
-				m = result.object.(map[string]interface{})
-			}
-
-			if m != nil && len(m) > 0 {
-				v, ok = m[read.Value]
-				token = typeOfToken(v)
-				if !ok {
-					return buildEmptyResult()
-				}
-			} else {
-				return buildEmptyResult()
-			}
-
-			result = Result{
-				Token:  token,
-				object: v,
-			}
-			status = DotStatus | BeginArrayIndexStatus
-			break
-		case BeginArrayIndex:
-			status = DotStatus | ArrayIndexStatus
-		case ArrayIndex:
-			a := result.object.(*[]interface{})
-			index, _ := strconv.Atoi(read.Value)
-			v := (*a)[index]
-			token := typeOfToken(v)
-			result = Result{
-				Token:  token,
-				object: v,
-			}
-			status = EndArrayIndexStatus
-		case EndArrayIndex:
-			status = DotStatus
-		case Dot:
-			if !includeGrammarTokenStatus(DotStatus, status) {
-				return buildEmptyResult()
-			}
-			status = KeyStatus
-
-		case EOF:
-			if includeGrammarTokenStatus(KeyStatus, status) {
-				// syntax error
-				return buildEmptyResult()
-			}
-			return result
-
-		}
+		return buildEmptyResult(), err
 	}
+	return getWithRootE(decode, grammar)
 }
 
 func GetWithArithmetic(json, grammar string) Result {
-	tokenize, err := ArithmeticTokenize(grammar)
+	decode, err := Decode(json)
 	if err != nil {
 		return buildEmptyResult()
 	}
+	return getWithArithmeticRoot(decode, grammar)
+}
 
-	decode, err := Decode(json)
+func getWithArithmeticRoot(root interface{}, grammar string) Result {
+	tokenize, err := ArithmeticTokenize(grammar)
 	if err != nil {
 		return buildEmptyResult()
 	}
-	root, ok := decode.(map[string]interface{})
-	if !ok {
-		return buildEmptyResult()
-	}
 
 	reader := NewArithmeticTokenReader(tokenize)
 	var builder strings.Builder
@@ -179,6 +133,22 @@ func GetWithArithmetic(json, grammar string) Result {
 	//return Result{}
 }
 
+// Get query grammar relative to the result, the result can be an object or an array.
+func (r Result) Get(grammar string) Result {
+	if !r.Exists() {
+		return buildEmptyResult()
+	}
+	return getWithRoot(r.object, grammar)
+}
+
+// GetWithArithmetic evaluate grammar relative to the result.
+func (r Result) GetWithArithmetic(grammar string) Result {
+	if !r.Exists() {
+		return buildEmptyResult()
+	}
+	return getWithArithmeticRoot(r.object, grammar)
+}
+
 // String return result of string
 func (r Result) String() string {
 	switch r.Token {
@@ -186,14 +156,14 @@ func (r Result) String() string {
 		return fmt.Sprint(r.object)
 	case Bool:
 		return fmt.Sprint(r.object)
-	//case Null:
-	//	return ""
+	case Null:
+		// null 和空字符串不同
+		return "null"
 	case Number:
-		i, _ := strconv.Atoi(fmt.Sprint(r.object))
-		return fmt.Sprintf("%d", i)
+		return fmt.Sprint(r.object)
 	case Float:
-		i, _ := strconv.ParseFloat(fmt.Sprint(r.object), 64)
-		return fmt.Sprintf("%f", i)
+		// RawNumber 保持原样，float64 用最短的表示
+		return interface2String(r.object)
 	case JSONObject:
 		return object2JSONString(r.object)
 	case ArrayObject:
@@ -212,7 +182,7 @@ func object2JSONString(object interface{}) string {
 		m := data
 		count := 0
 		for s, v := range m {
-			builder.WriteString(fmt.Sprintf("\"%s\"", s))
+			builder.WriteString(quoteString(s))
 			builder.WriteByte(':')
 
 			switch vv := v.(type) {
@@ -263,18 +233,38 @@ func object2JSONString(object interface{}) string {
 func interface2String(v interface{}) string {
 	switch vv := v.(type) {
 	case string:
-		return fmt.Sprintf("\"%s\"", v)
+		return quoteString(vv)
 	case int:
 		return strconv.Itoa(vv)
+	case int64:
+		return strconv.FormatInt(vv, 10)
+	case uint64:
+		return strconv.FormatUint(vv, 10)
 	case float64:
-		return fmt.Sprintf("%f", vv)
+		f, _ := formatFloat(vv, 64)
+		return f
+	case RawNumber:
+		return vv.String()
+	case *big.Int:
+		return vv.String()
+	case *big.Float:
+		return vv.Text('g', -1)
 	case bool:
 		return strconv.FormatBool(vv)
+	case NullValue:
+		return "null"
 	default:
 		return ""
 	}
 }
 
+// quoteString return s as a quoted JSON string.
+func quoteString(s string) string {
+	var buf bytes.Buffer
+	writeString(&buf, s)
+	return buf.String()
+}
+
 func (r Result) Bool() bool {
 	switch r.Token {
 	case String:
@@ -327,8 +317,8 @@ func (r Result) Float() float64 {
 			return 0.0
 		}
 	case Number:
-		v, _ := strconv.Atoi(fmt.Sprint(r.object))
-		return float64(v)
+		v, _ := strconv.ParseFloat(fmt.Sprint(r.object), 64)
+		return v
 	case Float:
 		v, _ := strconv.ParseFloat(fmt.Sprint(r.object), 64)
 		return v
@@ -339,12 +329,16 @@ func (r Result) Float() float64 {
 
 // Map return map for object
 func (r Result) Map() map[string]interface{} {
-	return r.object.(map[string]interface{})
+	m, _ := r.object.(map[string]interface{})
+	return m
 }
 
 // Array return array for object
 func (r Result) Array() []interface{} {
-	arr := r.object.(*[]interface{})
+	arr, ok := r.object.(*[]interface{})
+	if !ok {
+		return nil
+	}
 	return covertSlice(arr)
 }
 
@@ -356,6 +350,12 @@ func covertSlice(d *[]interface{}) []interface{} {
 	return s
 }
 
+// IsNull check if the value is JSON null.
+func (r Result) IsNull() bool {
+	return r.Token == Null
+}
+
+// Exists check if the value exists, it is true for null.
 func (r Result) Exists() bool {
 	return r.object != nil
 }
@@ -372,12 +372,20 @@ func typeOfToken(v interface{}) (token Token) {
 	switch v.(type) {
 	case string:
 		token = String
-	case int:
+	case int, int64, uint64, *big.Int:
 		token = Number
-	case float64:
+	case float64, *big.Float:
 		token = Float
+	case RawNumber:
+		if v.(RawNumber).IsInteger() {
+			token = Number
+		} else {
+			token = Float
+		}
 	case bool:
 		token = Bool
+	case NullValue:
+		token = Null
 	case map[string]interface{}:
 		token = JSONObject
 	case *[]interface{}:
@@ -385,3 +393,13 @@ func typeOfToken(v interface{}) (token Token) {
 	}
 	return
 }
+
+// isBlank check if str only has whitespace.
+func isBlank(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if !isWhitespace(str[i]) {
+			return false
+		}
+	}
+	return true
+}
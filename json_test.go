@@ -57,7 +57,7 @@ func TestDecode5(t *testing.T) {
 	v := decode.(map[string]interface{})
 	assert.Equal(t, v["name"], "cj")
 	assert.Equal(t, v["age"], true)
-	assert.Equal(t, v["x"], "")
+	assert.Equal(t, v["x"], JSONNull)
 }
 func TestDecode6(t *testing.T) {
 	str := `{"name":"cj", "age":{"a":"a","b":"b","c":true}, "e":"e"}`
@@ -127,8 +127,8 @@ func TestDecode11(t *testing.T) {
 	v := decode.(map[string]interface{})
 	assert.Equal(t, v["name"], "cj")
 	strings := v["age"].(*[]interface{})
-	assert.Equal(t, (*strings)[0], "")
-	assert.Equal(t, (*strings)[1], "")
+	assert.Equal(t, (*strings)[0], JSONNull)
+	assert.Equal(t, (*strings)[1], JSONNull)
 	assert.Equal(t, v["e"], "e")
 }
 func TestDecode12(t *testing.T) {
@@ -267,7 +267,7 @@ func TestDecode18(t *testing.T) {
 	assert.Equal(t, (*glossSeeAlso)[0], "GML")
 	assert.Equal(t, (*glossSeeAlso)[1], "XML")
 	assert.Equal(t, (*glossSeeAlso)[2], true)
-	assert.Equal(t, (*glossSeeAlso)[3], "")
+	assert.Equal(t, (*glossSeeAlso)[3], JSONNull)
 	assert.Equal(t, glossEntry["GlossSee"], "markup")
 }
 
@@ -459,10 +459,10 @@ func TestJSONGet2(t *testing.T) {
 
 	list = Get(str, "list2.obj2[0].obj3.list3[2]")
 	assert.Equal(t, list.Bool(), false)
-	assert.Equal(t, list.String(), "")
+	assert.Equal(t, list.String(), "null")
 
 	list = Get(str, "list2.obj2[0].obj3.list3[3]")
-	assert.Equal(t, list.String(), "10.100000")
+	assert.Equal(t, list.String(), "10.1")
 	list = Get(str, "list2")
 	fmt.Println(list.String())
 	list = Get(str, "list2.obj2[0].obj3.list3")
@@ -654,3 +654,300 @@ func TestResultArray_String(t *testing.T) {
 	get = Get(str, "a.b")
 	fmt.Println(get.String())
 }
+
+func TestEscapeRoundTrip(t *testing.T) {
+	str := `{"a":{"k\"ey":"line1\nline2\t\"quoted\" \\ \u0001 😀"}}`
+	get := Get(str, "a")
+	fmt.Println(get.String())
+	assert.Equal(t, get.String(), `{"k\"ey":"line1\nline2\t\"quoted\" \\ \u0001 😀"}`)
+	assert.Equal(t, Get(`{"k":"\\\"\n"}`, "k").String(), "\\\"\n")
+
+	get = Get(`{"a":["\"", "\\"]}`, "a")
+	assert.Equal(t, get.String(), `["\"","\\"]`)
+}
+
+func TestNull(t *testing.T) {
+	str := `{"bio":null,"name":"","list":[null,1],"obj":{"a":null}}`
+	bio := Get(str, "bio")
+	assert.Equal(t, bio.Exists(), true)
+	assert.Equal(t, bio.IsNull(), true)
+	assert.Equal(t, bio.Token, Token(Null))
+	assert.Equal(t, bio.String(), "null")
+
+	name := Get(str, "name")
+	assert.Equal(t, name.Exists(), true)
+	assert.Equal(t, name.IsNull(), false)
+	assert.Equal(t, name.Token, Token(String))
+
+	assert.Equal(t, Get(str, "list[0]").IsNull(), true)
+	assert.Equal(t, Get(str, "abc").Exists(), false)
+	assert.Equal(t, Get(str, "abc").IsNull(), false)
+	assert.Equal(t, Get(str, "obj").String(), `{"a":null}`)
+	assert.Equal(t, Get(str, "list").String(), `[null,1]`)
+
+	var v struct {
+		Bio  *string     `json:"bio"`
+		Name string      `json:"name"`
+		List []*int      `json:"list"`
+		Obj  interface{} `json:"obj"`
+	}
+	s := "x"
+	v.Bio = &s
+	err := Unmarshal(str, &v)
+	assert.Nil(t, err)
+	assert.Nil(t, v.Bio)
+	assert.Nil(t, v.List[0])
+	assert.Equal(t, *v.List[1], 1)
+	assert.Equal(t, v.Obj, map[string]interface{}{"a": nil})
+
+	decode, err := Decode(str)
+	assert.Nil(t, err)
+	marshal, err := Marshal(decode)
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"bio":null,"list":[null,1],"name":"","obj":{"a":null}}`)
+}
+
+func TestResult_Get(t *testing.T) {
+	str := `{"person":{"name":{"first":"bob"},"age":10,"list":[{"a":1},{"a":2}],"matrix":[[1,2],[3,4]]}}`
+	person := Get(str, "person")
+	assert.Equal(t, person.Get("name.first").String(), "bob")
+	name := person.Get("name")
+	assert.Equal(t, name.Get("first").String(), "bob")
+	assert.Equal(t, name.Get("last").Exists(), false)
+
+	list := person.Get("list")
+	assert.Equal(t, list.Get("[1].a").Int(), 2)
+	assert.Equal(t, list.Get("[0]").Get("a").Int(), 1)
+	assert.Equal(t, list.Get("a").Exists(), false)
+	assert.Equal(t, person.Get("matrix[1][0]").Int(), 3)
+	assert.Equal(t, person.Get("matrix").Get("[0][1]").Int(), 2)
+
+	assert.Equal(t, person.GetWithArithmetic("age + list[1].a").Int(), 12)
+	assert.Equal(t, Get(str, "abc").Get("a").Exists(), false)
+	assert.Equal(t, Get(str, "abc").GetWithArithmetic("a").Exists(), false)
+}
+
+func TestGetArrayRoot(t *testing.T) {
+	str := `[{"name":"bob","age":10},{"name":"alice","age":20}]`
+	assert.Equal(t, Get(str, "[0].name").String(), "bob")
+	assert.Equal(t, Get(str, "[1].age").Int(), 20)
+	assert.Equal(t, Get(str, "name").Exists(), false)
+	assert.Equal(t, Get(str, "").Token, Token(ArrayObject))
+	assert.Equal(t, len(Get(str, "").Array()), 2)
+	assert.Equal(t, GetWithArithmetic(str, "[0].age + [1].age").Int(), 30)
+
+	assert.Equal(t, Get(`[[1,2],[3,4]]`, "[1][0]").Int(), 3)
+
+	doc, err := ParseDocument(str)
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Get("[1].name").String(), "alice")
+}
+
+func TestGetScalarRoot(t *testing.T) {
+	assert.Equal(t, Get(`"abc"`, "").String(), "abc")
+	assert.Equal(t, Get(`""`, "").Exists(), true)
+	assert.Equal(t, Get(` 10 `, "").Int(), 10)
+	assert.Equal(t, Get(`-1.5`, "").Float(), -1.5)
+	assert.Equal(t, Get(`true`, "").Bool(), true)
+	assert.Equal(t, Get(`false`, "").Token, Token(Bool))
+	assert.Equal(t, Get(`null`, "").IsNull(), true)
+	assert.Equal(t, Get(`"abc"`, "a").Exists(), false)
+	assert.Equal(t, Get(`{"a":1}`, "").Get("a").Int(), 1)
+
+	for _, str := range []string{`1 2`, `"a" "b"`, `true,`, `1]`} {
+		_, err := Decode(str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestGetE(t *testing.T) {
+	str := `{"name":"bob","list":[1,2,3],"obj":{"a":1}}`
+	get, err := GetE(str, "list[1]")
+	assert.Nil(t, err)
+	assert.Equal(t, get.Int(), 2)
+
+	get, err = GetE(str, "name[5]")
+	assert.NotNil(t, err)
+	assert.Equal(t, get.Exists(), false)
+	assert.Equal(t, err.Error(), "path 'name[5]': segment '[5]' parent is String, not ArrayObject")
+
+	_, err = GetE(str, "list[5]")
+	assert.Equal(t, err.Error(), "path 'list[5]': segment '[5]' index out of range with length 3")
+	e := err.(*PathError)
+	assert.Equal(t, e.Segment, "[5]")
+
+	_, err = GetE(str, "obj.b")
+	assert.Equal(t, err.Error(), "path 'obj.b': segment 'b' key not found")
+
+	_, err = GetE(str, "list.a")
+	assert.Equal(t, err.Error(), "path 'list.a': segment 'a' parent is ArrayObject, not JSONObject")
+
+	_, err = GetE(str, "obj.")
+	assert.Equal(t, err.Error(), "path 'obj.': unexpected end of path")
+
+	_, err = GetE(str, "l[10.a")
+	assert.NotNil(t, err)
+	fmt.Println(err)
+
+	_, err = GetE(`{"a":`, "a")
+	assert.NotNil(t, err)
+
+	// never panic
+	assert.Equal(t, Get(str, "name[5]").Exists(), false)
+	assert.Equal(t, Get(str, "list[3]").Exists(), false)
+	assert.Equal(t, Get(str, "obj[0]").Exists(), false)
+	assert.Equal(t, Get(str, "list[0][0]").Exists(), false)
+	assert.Nil(t, Get(str, "name").Map())
+	assert.Nil(t, Get(str, "name").Array())
+
+	doc, err := ParseDocument(str)
+	assert.Nil(t, err)
+	_, err = doc.GetE("list[9]")
+	assert.NotNil(t, err)
+}
+
+func TestGetNegativeIndex(t *testing.T) {
+	str := `{"events":[{"id":1},{"id":2},{"id":3}],"name":"bob","matrix":[[1,2],[3,4]]}`
+	assert.Equal(t, Get(str, "events[-1].id").Int(), 3)
+	assert.Equal(t, Get(str, "events[-3].id").Int(), 1)
+	assert.Equal(t, Get(str, "events[-4]").Exists(), false)
+	assert.Equal(t, Get(str, "matrix[-1][-2]").Int(), 3)
+	assert.Equal(t, Get(str, "events.#").Int(), 3)
+	assert.Equal(t, Get(str, "matrix[0].#").Int(), 2)
+	assert.Equal(t, Get(`[1,2]`, "#").Int(), 2)
+	assert.Equal(t, Get(str, "name.#").Exists(), false)
+	assert.Equal(t, Get(str, "events.#.").Exists(), false)
+	assert.Equal(t, GetWithArithmetic(str, "events.# + events[-1].id").Int(), 6)
+
+	_, err := GetE(str, "events[-4]")
+	assert.Equal(t, err.Error(), "path 'events[-4]': segment '[-4]' index out of range with length 3")
+}
+
+func TestGetProjection(t *testing.T) {
+	str := `{"people":[{"name":"bob","age":10},{"name":"alice","age":20},{"age":30}],
+"obj":{"b":{"name":"b"},"a":{"name":"a"}},"teams":[{"members":[{"id":1},{"id":2}]},{"members":[{"id":3}]}]}`
+	names := Get(str, "people[*].name")
+	assert.Equal(t, names.Token, Token(ArrayObject))
+	assert.Equal(t, names.Array(), []interface{}{"bob", "alice"})
+	assert.Equal(t, names.String(), `["bob","alice"]`)
+	assert.Equal(t, Get(str, "people.#.name").Array(), []interface{}{"bob", "alice"})
+	assert.Equal(t, Get(str, "people.#.age").Array(), []interface{}{10, 20, 30})
+	assert.Equal(t, Get(str, "people[*]").Get("#").Int(), 3)
+	assert.Equal(t, Get(str, "people.#").Int(), 3)
+
+	assert.Equal(t, Get(str, "obj.*.name").Array(), []interface{}{"a", "b"})
+	assert.Equal(t, Get(str, "obj.*").Get("#").Int(), 2)
+	assert.Equal(t, Get(str, "people.*.age").Array(), []interface{}{10, 20, 30})
+
+	assert.Equal(t, Get(str, "teams[*].members[*].id").String(), `[[1,2],[3]]`)
+	assert.Equal(t, Get(str, "teams[*].members[0].id").Array(), []interface{}{1, 3})
+	assert.Equal(t, Get(str, "people[*].abc").String(), `[]`)
+	assert.Equal(t, Get(`[{"a":1},{"a":2}]`, "#.a").Array(), []interface{}{1, 2})
+
+	assert.Equal(t, Get(str, "people[*].").Exists(), false)
+	_, err := GetE(str, "people[*].")
+	assert.NotNil(t, err)
+	assert.Equal(t, Get(str, "obj.b.name.*").Exists(), false)
+}
+
+func TestGetSlice(t *testing.T) {
+	str := `{"items":[0,1,2,3,4,5],"people":[{"name":"bob"},{"name":"alice"},{"name":"tom"}]}`
+	r := Get(str, "items[1:4]")
+	fmt.Println(r)
+	assert.Equal(t, r.Token, Token(ArrayObject))
+	assert.Equal(t, r.Array(), []interface{}{1, 2, 3})
+	assert.Equal(t, Get(str, "items[:3]").Array(), []interface{}{0, 1, 2})
+	assert.Equal(t, Get(str, "items[-2:]").Array(), []interface{}{4, 5})
+	assert.Equal(t, Get(str, "items[::2]").Array(), []interface{}{0, 2, 4})
+	assert.Equal(t, Get(str, "items[1::2]").Array(), []interface{}{1, 3, 5})
+	assert.Equal(t, Get(str, "items[::-1]").Array(), []interface{}{5, 4, 3, 2, 1, 0})
+	assert.Equal(t, Get(str, "items[-1:-3:-1]").Array(), []interface{}{5, 4})
+	assert.Equal(t, Get(str, "items[:]").Get("#").Int(), 6)
+	assert.Equal(t, Get(str, "items[2:100]").Array(), []interface{}{2, 3, 4, 5})
+	assert.Equal(t, Get(str, "items[4:2]").String(), `[]`)
+	assert.Equal(t, Get(str, "people[:2].name").Array(), []interface{}{"bob", "alice"})
+	assert.Equal(t, Get(`[[1,2,3],[4,5]]`, "[*][:1]").String(), `[[1],[4]]`)
+
+	_, err := GetE(str, "items[::0]")
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	_, err = GetE(str, "items[1:2:3:4]")
+	assert.NotNil(t, err)
+	_, err = GetE(str, "items[1-:2]")
+	assert.NotNil(t, err)
+	_, err = GetE(str, "people[0][1:2]")
+	assert.NotNil(t, err)
+}
+
+func TestGetDescent(t *testing.T) {
+	str := `{"id":1,"store":{"book":[{"id":2,"price":8.5,"author":{"id":3}},{"price":12}],"bicycle":{"price":19.9,"token":"abc"}},
+"users":[{"password":"p1","profile":{"password":"p2"}}]}`
+	ids := Get(str, "..id")
+	fmt.Println(ids)
+	assert.Equal(t, ids.Token, Token(ArrayObject))
+	assert.Equal(t, ids.Array(), []interface{}{1, 2, 3})
+	assert.Equal(t, Get(str, "store..price").Array(), []interface{}{19.9, 8.5, 12})
+	assert.Equal(t, Get(str, "..password").Array(), []interface{}{"p1", "p2"})
+	assert.Equal(t, Get(str, "..token").Array(), []interface{}{"abc"})
+	assert.Equal(t, Get(str, "..book[0].price").Array(), []interface{}{8.5})
+	assert.Equal(t, Get(str, "store.book..id").Array(), []interface{}{2, 3})
+	assert.Equal(t, Get(str, "store.book[0]..id").Array(), []interface{}{2, 3})
+	assert.Equal(t, Get(str, "..book[?(@.price > 10)].price").String(), `[[12]]`)
+	assert.Equal(t, Get(str, "..abc").String(), `[]`)
+	assert.Equal(t, Get(`[{"a":1},[{"a":2}]]`, "..a").Array(), []interface{}{1, 2})
+
+	_, err := GetE(str, "store..")
+	assert.NotNil(t, err)
+	_, err = GetE(str, "store...price")
+	assert.NotNil(t, err)
+}
+
+func TestGetQuotedKey(t *testing.T) {
+	str := `{"first-name":"bob","key with spaces":1,"a.b":{"c[0]":2},"名字":"张三","$ref":"#/a","it's":3,"q\"q":4,
+"people":[{"first-name":"alice","e-mail":"a@b.c"},{"first-name":"tom"}]}`
+	assert.Equal(t, Get(str, `["first-name"]`).String(), "bob")
+	assert.Equal(t, Get(str, `'first-name'`).String(), "bob")
+	assert.Equal(t, Get(str, `'key with spaces'`).Int(), 1)
+	assert.Equal(t, Get(str, `["key with spaces"]`).Int(), 1)
+	assert.Equal(t, Get(str, `'a.b'.'c[0]'`).Int(), 2)
+	assert.Equal(t, Get(str, `['a.b']["c[0]"]`).Int(), 2)
+	assert.Equal(t, Get(str, `名字`).String(), "张三")
+	assert.Equal(t, Get(str, `["名字"]`).String(), "张三")
+	assert.Equal(t, Get(str, `'$ref'`).String(), "#/a")
+	assert.Equal(t, Get(str, `"it's"`).Int(), 3)
+	assert.Equal(t, Get(str, `'it\'s'`).Int(), 3)
+	assert.Equal(t, Get(str, `'q"q'`).Int(), 4)
+	assert.Equal(t, Get(str, `people[1]["first-name"]`).String(), "tom")
+	assert.Equal(t, Get(str, `people[0].'e-mail'`).String(), "a@b.c")
+	assert.Equal(t, Get(str, `people[*]["first-name"]`).Array(), []interface{}{"alice", "tom"})
+	assert.Equal(t, Get(str, `..["first-name"]`).Array(), []interface{}{"bob", "alice", "tom"})
+	assert.Equal(t, Get(str, `people[?(@["first-name"] == "tom")].'first-name'`).Array(), []interface{}{"tom"})
+	assert.Equal(t, Get(str, `'first-name'`).Get(`'x'`).Exists(), false)
+
+	_, err := GetE(str, `['first-name'`)
+	assert.NotNil(t, err)
+	_, err = GetE(str, `['first-name']x`)
+	assert.NotNil(t, err)
+	_, err = GetE(str, `'first-name'people`)
+	assert.NotNil(t, err)
+	_, err = GetE(str, `'first-name`)
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	r, err := GetE(str, `["last-name"]`)
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	assert.Equal(t, r.Exists(), false)
+}
+
+func TestResultFloatString(t *testing.T) {
+	str := `{"a":0.1234567,"b":1e-10,"c":1e400,"d":123456789012345678901234.5,"e":2.50}`
+	assert.Equal(t, Get(str, "a").String(), "0.1234567")
+	assert.Equal(t, Get(str, "b").String(), "1e-10")
+	assert.Equal(t, Get(str, "c").String(), "1e400")
+	assert.Equal(t, Get(str, "d").String(), "1.2345678901234569e+23")
+
+	root, err := DecodeWithNumberMode(str, NumberModeRaw)
+	assert.Nil(t, err)
+	assert.Equal(t, getWithRoot(root, "d").String(), "123456789012345678901234.5")
+	assert.Equal(t, getWithRoot(root, "e").String(), "2.50")
+}
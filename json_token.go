@@ -1,6 +1,10 @@
 package xjson
 
-import "errors"
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
 type Token string
 
@@ -39,246 +43,33 @@ const (
 type TokenType struct {
 	T     Token
 	Value string
+	// Offset is the byte offset of the token in input.
+	Offset int
 }
 
+// Tokenize read all the tokens of str, use Lexer to read them one by one without allocation.
 func Tokenize(str string) ([]*TokenType, error) {
-	//bytes := []byte(str)
+	lexer := NewLexerString(str)
 	var result []*TokenType
-	var values []byte
-	status := Init
-	for i := 0; i < len(str); i++ {
-		b := str[i]
-		switch status {
-		case Init:
-			status, values = InitStatus(b, values)
-			break
-		case BeginObject:
-			t := &TokenType{
-				T:     BeginObject,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-		case EndObject:
-			t := &TokenType{
-				T:     EndObject,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-		case BeginString:
-			if b == '"' && str[i-1] != '\\' {
-				//values = append(values, b)
-				status = EndString
-			} else if b == '\\' {
-				// skip escape
-				continue
-			} else {
-				values = append(values, b)
-			}
-		case EndString:
-			t := &TokenType{
-				T:     String,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case Number:
-			if b == '.' {
-				values = append(values, b)
-				status = Float
-				break
-			}
-			if isDigit(b) {
-				values = append(values, b)
-			} else {
-				t := &TokenType{
-					T:     Number,
-					Value: string(values),
-				}
-				result = append(result, t)
-				values = nil
-				status, values = InitStatus(b, values)
-				break
-			}
-		case Float:
-			if b == '.' {
-				return nil, errors.New("invalid float")
-			}
-			if isDigit(b) {
-				values = append(values, b)
-			} else {
-				t := &TokenType{
-					T:     Float,
-					Value: string(values),
-				}
-				result = append(result, t)
-				values = nil
-				status, values = InitStatus(b, values)
-				break
-			}
-		case SepColon:
-			t := &TokenType{
-				T:     SepColon,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case SepComma:
-			t := &TokenType{
-				T:     SepComma,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case BeginArray:
-			t := &TokenType{
-				T:     BeginArray,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case EndArray:
-			t := &TokenType{
-				T:     EndArray,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case True1:
-			if b == 'r' {
-				values = append(values, b)
-				status = True2
-				break
-			} else {
-				return nil, errors.New("invalid bool true")
-			}
-		case True2:
-			if b == 'u' {
-				values = append(values, b)
-				status = True3
-				break
-			} else {
-				return nil, errors.New("invalid bool true")
-			}
-		case True3:
-			if b == 'e' {
-				values = append(values, b)
-				status = True
-				break
-			} else {
-				return nil, errors.New("invalid bool true")
-			}
-		case True:
-			t := &TokenType{
-				T:     True,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case Null1:
-			if b == 'u' {
-				values = append(values, b)
-				status = Null2
-				break
-			} else {
-				return nil, errors.New("invalid null")
-			}
-		case Null2:
-			if b == 'l' {
-				values = append(values, b)
-				status = Null3
-				break
-			} else {
-				return nil, errors.New("invalid null")
-			}
-		case Null3:
-			if b == 'l' {
-				values = append(values, b)
-				status = Null
-				break
-			} else {
-				return nil, errors.New("invalid null")
-			}
-		case Null:
-			t := &TokenType{
-				T:     Null,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
-		case False1:
-			if b == 'a' {
-				values = append(values, b)
-				status = False2
-				break
-			} else {
-				return nil, errors.New("invalid bool false")
-			}
-		case False2:
-			if b == 'l' {
-				values = append(values, b)
-				status = False3
-				break
-			} else {
-				return nil, errors.New("invalid bool false")
-			}
-		case False3:
-			if b == 's' {
-				values = append(values, b)
-				status = False4
-				break
-			} else {
-				return nil, errors.New("invalid bool false")
-			}
-		case False4:
-			if b == 'e' {
-				values = append(values, b)
-				status = False
-				break
-			} else {
-				return nil, errors.New("invalid bool false")
-			}
-		case False:
-			t := &TokenType{
-				T:     False,
-				Value: string(values),
-			}
-			result = append(result, t)
-			values = nil
-			status, values = InitStatus(b, values)
-			break
+	for {
+		t, value, err := lexer.Next()
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	// 解析最后一个
-	if len(values) > 0 {
-		t := &TokenType{
-			T:     status,
-			Value: string(values),
+		if t == EndJson {
+			return result, nil
 		}
-		result = append(result, t)
+		result = append(result, &TokenType{
+			T:      t,
+			Value:  string(value),
+			Offset: lexer.Offset(),
+		})
 	}
-
-	return result, nil
 }
 
+// InitStatus return the status of the old tokenizer for the first byte of a token.
+//
+// Deprecated: Tokenize use Lexer now, use Lexer.Next to read the tokens.
 func InitStatus(b byte, values []byte) (Token, []byte) {
 
 	if b == '{' {
@@ -329,6 +120,76 @@ func InitStatus(b byte, values []byte) (Token, []byte) {
 	return Init, values
 }
 
+// unescape decode the escape sequence after '\\' at str[i], append the result to values
+// and return the count of extra bytes consumed.
+func unescape(str []byte, i int, values []byte) ([]byte, int, error) {
+	switch str[i] {
+	case '"', '\\', '/':
+		return append(values, str[i]), 0, nil
+	case 'b':
+		return append(values, '\b'), 0, nil
+	case 'f':
+		return append(values, '\f'), 0, nil
+	case 'n':
+		return append(values, '\n'), 0, nil
+	case 'r':
+		return append(values, '\r'), 0, nil
+	case 't':
+		return append(values, '\t'), 0, nil
+	case 'u':
+		r, ok := readHex4(str, i+1)
+		if !ok {
+			return nil, 0, errors.New("invalid unicode escape")
+		}
+		n := 4
+		if utf16.IsSurrogate(r) {
+			// 代理对 \uD83D\uDE00
+			r2, ok := readHex4(str, i+7)
+			if ok && i+6 < len(str) && str[i+5] == '\\' && str[i+6] == 'u' {
+				if c := utf16.DecodeRune(r, r2); c != utf8.RuneError {
+					r = c
+					n += 6
+				} else {
+					r = utf8.RuneError
+				}
+			} else {
+				r = utf8.RuneError
+			}
+		}
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], r)
+		return append(values, buf[:size]...), n, nil
+	default:
+		return nil, 0, errors.New("invalid escape '\\" + string(str[i]) + "'")
+	}
+}
+
+func readHex4(str []byte, i int) (rune, bool) {
+	if i+4 > len(str) {
+		return 0, false
+	}
+	var r rune
+	for j := i; j < i+4; j++ {
+		c := str[j]
+		switch {
+		case '0' <= c && c <= '9':
+			c = c - '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	return r, true
+}
+
+func isWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
 func isDigit(b byte) bool {
 	return b >= 48 && b <= 57
 }
@@ -352,3 +213,17 @@ func (t *TokenReader) Read() *TokenType {
 	t.pos += 1
 	return tokenType
 }
+
+// Next is like Lexer.Next, so that the parser can pull tokens from a TokenReader.
+func (t *TokenReader) Next() (Token, []byte, error) {
+	tokenType := t.Read()
+	return tokenType.T, []byte(tokenType.Value), nil
+}
+
+// Offset return the offset of the last token.
+func (t *TokenReader) Offset() int {
+	if t.pos == 0 || int(t.pos) > len(t.tokens) {
+		return -1
+	}
+	return t.tokens[t.pos-1].Offset
+}
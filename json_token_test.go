@@ -118,3 +118,23 @@ func TestEscapeToken(t *testing.T) {
 		fmt.Printf("%s  %s\n", tokenType.T, tokenType.Value)
 	}
 }
+
+func TestEscapeToken2(t *testing.T) {
+	str := `{"a":"\n\t\r\b\f\/\\\"","b":"a\\","c":"中文","d":"😀","e":"\ud83d"}`
+	tokenize, err := Tokenize(str)
+	assert.Nil(t, err)
+	assert.Equal(t, tokenize[3].Value, "\n\t\r\b\f/\\\"")
+	assert.Equal(t, tokenize[7].Value, "a\\")
+	assert.Equal(t, tokenize[11].Value, "中文")
+	assert.Equal(t, tokenize[15].Value, "😀")
+	assert.Equal(t, tokenize[19].Value, "�")
+
+	str = `{"a":"\x"}`
+	_, err = Tokenize(str)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	str = `{"a":"\u12"}`
+	_, err = Tokenize(str)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+}
@@ -0,0 +1,993 @@
+package xjson
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// JSONPath query json by a RFC 9535 JSONPath expression like $.store.book[?@.price < 10].title,
+// the result is an ArrayObject of all the matched values.
+func JSONPath(json, expr string) (Result, error) {
+	decode, err := Decode(json)
+	if err != nil {
+		return buildEmptyResult(), err
+	}
+	return jsonPathWithRoot(decode, expr)
+}
+
+func jsonPathWithRoot(root interface{}, expr string) (Result, error) {
+	query, err := parseJSONPath(expr)
+	if err != nil {
+		return buildEmptyResult(), err
+	}
+	nodes := query.eval(root, root)
+	if nodes == nil {
+		nodes = make([]interface{}, 0)
+	}
+	return Result{
+		Token:  ArrayObject,
+		object: &nodes,
+	}, nil
+}
+
+// jpQuery is $ or @ followed by segments.
+type jpQuery struct {
+	relative bool
+	segments []*jpSegment
+}
+
+// singular check if the query select at most one node, only name and index selectors are allowed.
+func (q *jpQuery) singular() bool {
+	for _, segment := range q.segments {
+		if segment.descendant || len(segment.selectors) != 1 {
+			return false
+		}
+		switch segment.selectors[0].(type) {
+		case *jpNameSelector, *jpIndexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (q *jpQuery) eval(current, root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	if q.relative {
+		nodes = []interface{}{current}
+	}
+	for _, segment := range q.segments {
+		var next []interface{}
+		for _, node := range nodes {
+			if segment.descendant {
+				// ..name 作用于 node 自己和它下面的每一个值
+				for _, d := range descendants(node, nil) {
+					next = segment.apply(d, root, next)
+				}
+			} else {
+				next = segment.apply(node, root, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+type jpSegment struct {
+	descendant bool
+	selectors  []jpSelector
+}
+
+func (s *jpSegment) apply(node, root interface{}, nodes []interface{}) []interface{} {
+	for _, selector := range s.selectors {
+		nodes = selector.selectNodes(node, root, nodes)
+	}
+	return nodes
+}
+
+// jpSelector append the selected children of node to nodes.
+type jpSelector interface {
+	selectNodes(node, root interface{}, nodes []interface{}) []interface{}
+}
+
+type jpNameSelector struct {
+	name string
+}
+
+func (s *jpNameSelector) selectNodes(node, _ interface{}, nodes []interface{}) []interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if v, ok := m[s.name]; ok {
+			nodes = append(nodes, v)
+		}
+	}
+	return nodes
+}
+
+type jpWildcardSelector struct{}
+
+func (s *jpWildcardSelector) selectNodes(node, _ interface{}, nodes []interface{}) []interface{} {
+	values, _ := childValues(node)
+	return append(nodes, values...)
+}
+
+type jpIndexSelector struct {
+	index int
+}
+
+func (s *jpIndexSelector) selectNodes(node, _ interface{}, nodes []interface{}) []interface{} {
+	if a, ok := node.(*[]interface{}); ok {
+		index := s.index
+		if index < 0 {
+			index += len(*a)
+		}
+		if index >= 0 && index < len(*a) {
+			nodes = append(nodes, (*a)[index])
+		}
+	}
+	return nodes
+}
+
+type jpSliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s *jpSliceSelector) selectNodes(node, _ interface{}, nodes []interface{}) []interface{} {
+	if a, ok := node.(*[]interface{}); ok && s.step != 0 {
+		nodes = append(nodes, sliceRange(*a, s.start, s.end, s.step)...)
+	}
+	return nodes
+}
+
+type jpFilterSelector struct {
+	expr jpLogical
+}
+
+func (s *jpFilterSelector) selectNodes(node, root interface{}, nodes []interface{}) []interface{} {
+	values, _ := childValues(node)
+	for _, v := range values {
+		if s.expr.test(v, root) {
+			nodes = append(nodes, v)
+		}
+	}
+	return nodes
+}
+
+// jpLogical is the expression of a filter selector.
+type jpLogical interface {
+	test(current, root interface{}) bool
+}
+
+type jpOr struct {
+	x, y jpLogical
+}
+
+func (e *jpOr) test(current, root interface{}) bool {
+	return e.x.test(current, root) || e.y.test(current, root)
+}
+
+type jpAnd struct {
+	x, y jpLogical
+}
+
+func (e *jpAnd) test(current, root interface{}) bool {
+	return e.x.test(current, root) && e.y.test(current, root)
+}
+
+type jpNot struct {
+	x jpLogical
+}
+
+func (e *jpNot) test(current, root interface{}) bool {
+	return !e.x.test(current, root)
+}
+
+// jpExistence is @.name, it is true when the query select any node.
+type jpExistence struct {
+	query *jpQuery
+}
+
+func (e *jpExistence) test(current, root interface{}) bool {
+	return len(e.query.eval(current, root)) > 0
+}
+
+type jpComparison struct {
+	op   string
+	x, y jpComparable
+}
+
+func (e *jpComparison) test(current, root interface{}) bool {
+	x, xok := e.x.value(current, root)
+	y, yok := e.y.value(current, root)
+	switch e.op {
+	case "==":
+		return jpEqual(x, xok, y, yok)
+	case "!=":
+		return !jpEqual(x, xok, y, yok)
+	case "<":
+		return jpLess(x, xok, y, yok)
+	case "<=":
+		return jpLess(x, xok, y, yok) || jpEqual(x, xok, y, yok)
+	case ">":
+		return jpLess(y, yok, x, xok)
+	case ">=":
+		return jpLess(y, yok, x, xok) || jpEqual(x, xok, y, yok)
+	}
+	return false
+}
+
+// jpEqual compare two values, the missing value (Nothing) is only equal to another missing value.
+func jpEqual(x interface{}, xok bool, y interface{}, yok bool) bool {
+	if !xok || !yok {
+		return xok == yok
+	}
+	return equalValues(x, y)
+}
+
+// jpLess only numbers and strings can be ordered.
+func jpLess(x interface{}, xok bool, y interface{}, yok bool) bool {
+	if !xok || !yok {
+		return false
+	}
+	c, ok := compareValues(x, y)
+	return ok && c < 0
+}
+
+// jpComparable is the operand of a comparison, ok is false when there is no value.
+type jpComparable interface {
+	value(current, root interface{}) (v interface{}, ok bool)
+}
+
+type jpLiteral struct {
+	v interface{}
+}
+
+func (e *jpLiteral) value(interface{}, interface{}) (interface{}, bool) {
+	return e.v, true
+}
+
+// jpSingularQuery is a singular query used as a value.
+type jpSingularQuery struct {
+	query *jpQuery
+}
+
+func (e *jpSingularQuery) value(current, root interface{}) (interface{}, bool) {
+	nodes := e.query.eval(current, root)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// jpType is the type of function parameters and results defined by RFC 9535.
+type jpType int
+
+const (
+	jpValueType jpType = iota
+	jpLogicalType
+	jpNodesType
+)
+
+type jpFunctionDef struct {
+	params []jpType
+	result jpType
+}
+
+var jpFunctions = map[string]jpFunctionDef{
+	"length": {params: []jpType{jpValueType}, result: jpValueType},
+	"count":  {params: []jpType{jpNodesType}, result: jpValueType},
+	"match":  {params: []jpType{jpValueType, jpValueType}, result: jpLogicalType},
+	"search": {params: []jpType{jpValueType, jpValueType}, result: jpLogicalType},
+	"value":  {params: []jpType{jpNodesType}, result: jpValueType},
+}
+
+// jpFunction is a function call, the args are *jpQuery for NodesType and jpComparable for ValueType.
+type jpFunction struct {
+	name string
+	args []interface{}
+}
+
+func (f *jpFunction) value(current, root interface{}) (interface{}, bool) {
+	switch f.name {
+	case "length":
+		v, ok := f.args[0].(jpComparable).value(current, root)
+		if !ok {
+			return nil, false
+		}
+		switch data := v.(type) {
+		case string:
+			return utf8.RuneCountInString(data), true
+		case *[]interface{}:
+			return len(*data), true
+		case map[string]interface{}:
+			return len(data), true
+		}
+		return nil, false
+	case "count":
+		return len(f.args[0].(*jpQuery).eval(current, root)), true
+	case "value":
+		nodes := f.args[0].(*jpQuery).eval(current, root)
+		if len(nodes) != 1 {
+			return nil, false
+		}
+		return nodes[0], true
+	}
+	return nil, false
+}
+
+func (f *jpFunction) test(current, root interface{}) bool {
+	switch f.name {
+	case "match", "search":
+		v, ok1 := f.args[0].(jpComparable).value(current, root)
+		p, ok2 := f.args[1].(jpComparable).value(current, root)
+		s, ok3 := v.(string)
+		pattern, ok4 := p.(string)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			return false
+		}
+		re, err := compileIRegexp(pattern, f.name == "match")
+		if err != nil {
+			return false
+		}
+		return re.MatchString(s)
+	}
+	return false
+}
+
+// maxCachedRegexps bound the cache, the patterns may be built dynamically by the callers.
+const maxCachedRegexps = 256
+
+// cachedRegexps cache the compiled patterns of match() and search().
+var cachedRegexps = newRegexpCache(maxCachedRegexps)
+
+// regexpCache is a LRU cache of the compiled patterns.
+type regexpCache struct {
+	mu   sync.Mutex
+	size int
+	// list keep the entries from the most recently used to the least
+	list  *list.List
+	items map[regexpKey]*list.Element
+}
+
+type regexpEntry struct {
+	key regexpKey
+	re  *regexp.Regexp
+}
+
+func newRegexpCache(size int) *regexpCache {
+	return &regexpCache{size: size, list: list.New(), items: make(map[regexpKey]*list.Element)}
+}
+
+func (c *regexpCache) get(key regexpKey) (*regexp.Regexp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(e)
+	return e.Value.(*regexpEntry).re, true
+}
+
+func (c *regexpCache) add(key regexpKey, re *regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.list.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.list.PushFront(&regexpEntry{key: key, re: re})
+	if c.list.Len() > c.size {
+		// 淘汰最久没有使用的
+		e := c.list.Back()
+		c.list.Remove(e)
+		delete(c.items, e.Value.(*regexpEntry).key)
+	}
+}
+
+// regexpKey is the key of cachedRegexps, the same pattern is compiled differently by match() and search().
+type regexpKey struct {
+	pattern string
+	full    bool
+}
+
+// compileIRegexp compile the I-Regexp (RFC 9485) pattern, full is true when the whole string must match.
+func compileIRegexp(pattern string, full bool) (*regexp.Regexp, error) {
+	key := regexpKey{pattern: pattern, full: full}
+	if re, ok := cachedRegexps.get(key); ok {
+		return re, nil
+	}
+	var builder strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		b := pattern[i]
+		switch {
+		case b == '\\' && i+1 < len(pattern):
+			builder.WriteByte(b)
+			i++
+			builder.WriteByte(pattern[i])
+			continue
+		case b == '[':
+			inClass = true
+		case b == ']':
+			inClass = false
+		case b == '.' && !inClass:
+			// I-Regexp 的 . 不匹配 \n 和 \r
+			builder.WriteString(`[^\n\r]`)
+			continue
+		}
+		builder.WriteByte(b)
+	}
+	expr := builder.String()
+	if full {
+		expr = `^(?:` + expr + `)$`
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	cachedRegexps.add(key, re)
+	return re, nil
+}
+
+// jpParser parse the JSONPath expression by the ABNF of RFC 9535.
+type jpParser struct {
+	str string
+	pos int
+}
+
+func parseJSONPath(expr string) (*jpQuery, error) {
+	p := &jpParser{str: expr}
+	if !p.eat('$') {
+		return nil, p.error("expected '$'")
+	}
+	query, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.str) {
+		return nil, p.error("unexpected character")
+	}
+	return query, nil
+}
+
+func (p *jpParser) error(msg string) error {
+	segment := ""
+	if p.pos < len(p.str) {
+		r, _ := utf8.DecodeRuneInString(p.str[p.pos:])
+		segment = string(r)
+	}
+	return newPathSyntaxError(p.str, segment, fmt.Sprintf("%s at offset %d", msg, p.pos))
+}
+
+func (p *jpParser) peek() byte {
+	if p.pos < len(p.str) {
+		return p.str[p.pos]
+	}
+	return 0
+}
+
+func (p *jpParser) eat(b byte) bool {
+	if p.peek() == b && p.pos < len(p.str) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *jpParser) skipSpace() {
+	for p.pos < len(p.str) && isWhitespace(p.str[p.pos]) {
+		p.pos++
+	}
+}
+
+// parseSegments parse the segments after $ or @, whitespace is allowed before every segment.
+func (p *jpParser) parseSegments(relative bool) (*jpQuery, error) {
+	query := &jpQuery{relative: relative}
+	for {
+		start := p.pos
+		p.skipSpace()
+		var segment *jpSegment
+		var err error
+		switch {
+		case strings.HasPrefix(p.str[p.pos:], ".."):
+			p.pos += 2
+			segment, err = p.parseDescendant()
+		case p.peek() == '.':
+			p.pos++
+			segment, err = p.parseDotSegment()
+		case p.peek() == '[':
+			segment, err = p.parseBracket()
+		default:
+			// 空白属于后面的表达式
+			p.pos = start
+			return query, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		query.segments = append(query.segments, segment)
+	}
+}
+
+func (p *jpParser) parseDescendant() (*jpSegment, error) {
+	if p.peek() == '[' {
+		segment, err := p.parseBracket()
+		if err != nil {
+			return nil, err
+		}
+		segment.descendant = true
+		return segment, nil
+	}
+	segment, err := p.parseDotSegment()
+	if err != nil {
+		return nil, err
+	}
+	segment.descendant = true
+	return segment, nil
+}
+
+// parseDotSegment parse .* or .name
+func (p *jpParser) parseDotSegment() (*jpSegment, error) {
+	if p.eat('*') {
+		return &jpSegment{selectors: []jpSelector{&jpWildcardSelector{}}}, nil
+	}
+	name := p.parseMemberName()
+	if name == "" {
+		return nil, p.error("expected member name")
+	}
+	return &jpSegment{selectors: []jpSelector{&jpNameSelector{name: name}}}, nil
+}
+
+// parseMemberName read ALPHA, '_', DIGIT and non-ASCII characters, DIGIT can't be the first one.
+func (p *jpParser) parseMemberName() string {
+	start := p.pos
+	for p.pos < len(p.str) {
+		b := p.str[p.pos]
+		if b >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(p.str[p.pos:])
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			p.pos += size
+			continue
+		}
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b == '_' || (isDigit(b) && p.pos > start) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.str[start:p.pos]
+}
+
+// parseBracket parse [selector, selector, ...]
+func (p *jpParser) parseBracket() (*jpSegment, error) {
+	p.pos++ // [
+	segment := &jpSegment{}
+	for {
+		p.skipSpace()
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		segment.selectors = append(segment.selectors, selector)
+		p.skipSpace()
+		if p.eat(']') {
+			return segment, nil
+		}
+		if !p.eat(',') {
+			return nil, p.error("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jpParser) parseSelector() (jpSelector, error) {
+	switch b := p.peek(); {
+	case b == '\'' || b == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jpNameSelector{name: name}, nil
+	case b == '*':
+		p.pos++
+		return &jpWildcardSelector{}, nil
+	case b == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		return &jpFilterSelector{expr: expr}, nil
+	case b == '-' || isDigit(b) || b == ':':
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.error("invalid selector")
+}
+
+// parseIndexOrSlice parse 1, -1, 1:2, ::-1
+func (p *jpParser) parseIndexOrSlice() (jpSelector, error) {
+	var bounds [3]*int
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			p.skipSpace()
+			if !p.eat(':') {
+				break
+			}
+			p.skipSpace()
+		}
+		if b := p.peek(); b == '-' || isDigit(b) {
+			n, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = &n
+		}
+		if i == 0 {
+			p.skipSpace()
+			if p.peek() != ':' {
+				if bounds[0] == nil {
+					return nil, p.error("invalid selector")
+				}
+				return &jpIndexSelector{index: *bounds[0]}, nil
+			}
+		}
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	return &jpSliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+// jpMaxInt is the max integer of I-JSON, 2^53-1.
+const jpMaxInt = 1<<53 - 1
+
+// parseInt parse "0" or an integer without leading zero, "-0" is invalid.
+func (p *jpParser) parseInt() (int, error) {
+	start := p.pos
+	p.eat('-')
+	digits := p.pos
+	for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+		p.pos++
+	}
+	literal := p.str[start:p.pos]
+	if p.pos == digits || (p.str[digits] == '0' && (p.pos-digits > 1 || digits > start)) {
+		p.pos = start
+		return 0, p.error("invalid integer")
+	}
+	n, err := strconv.Atoi(literal)
+	if err != nil || n > jpMaxInt || n < -jpMaxInt {
+		p.pos = start
+		return 0, p.error("integer out of range")
+	}
+	return n, nil
+}
+
+// parseString parse the single or double quoted string.
+func (p *jpParser) parseString() (string, error) {
+	quote := p.str[p.pos]
+	p.pos++
+	var values []byte
+	for p.pos < len(p.str) {
+		b := p.str[p.pos]
+		switch {
+		case b == quote:
+			p.pos++
+			return string(values), nil
+		case b == '\\':
+			p.pos++
+			if p.pos >= len(p.str) {
+				return "", p.error("unterminated string")
+			}
+			if p.str[p.pos] == '\'' && quote == '\'' {
+				values = append(values, '\'')
+				p.pos++
+				continue
+			}
+			if p.str[p.pos] == '"' && quote != '"' {
+				return "", p.error("invalid escape")
+			}
+			// 转义序列最长是 \uXXXX\uXXXX
+			end := p.pos + 11
+			if end > len(p.str) {
+				end = len(p.str)
+			}
+			v, n, err := unescape([]byte(p.str[p.pos:end]), 0, values)
+			if err != nil {
+				return "", p.error(err.Error())
+			}
+			values = v
+			p.pos += n + 1
+		case b < 0x20:
+			return "", p.error("invalid control character in string")
+		default:
+			values = append(values, b)
+			p.pos++
+		}
+	}
+	return "", p.error("unterminated string")
+}
+
+func (p *jpParser) parseLogicalOr() (jpLogical, error) {
+	x, err := p.parseLogicalAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.str[p.pos:], "||") {
+			return x, nil
+		}
+		p.pos += 2
+		p.skipSpace()
+		y, err := p.parseLogicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &jpOr{x: x, y: y}
+	}
+}
+
+func (p *jpParser) parseLogicalAnd() (jpLogical, error) {
+	x, err := p.parseBasic()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !strings.HasPrefix(p.str[p.pos:], "&&") {
+			return x, nil
+		}
+		p.pos += 2
+		p.skipSpace()
+		y, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		x = &jpAnd{x: x, y: y}
+	}
+}
+
+// parseBasic parse the paren expression, comparison or test expression.
+func (p *jpParser) parseBasic() (jpLogical, error) {
+	if p.peek() == '!' {
+		p.pos++
+		p.skipSpace()
+		x, err := p.parseBasicOperand()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := x.(*jpComparison); ok {
+			// !@.a == 1 是非法的
+			return nil, p.error("comparison can't be negated without parentheses")
+		}
+		return &jpNot{x: x}, nil
+	}
+	return p.parseBasicOperand()
+}
+
+func (p *jpParser) parseBasicOperand() (jpLogical, error) {
+	if p.eat('(') {
+		p.skipSpace()
+		x, err := p.parseLogicalOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.eat(')') {
+			return nil, p.error("expected ')'")
+		}
+		return &jpParen{x: x}, nil
+	}
+
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	start := p.pos
+	p.skipSpace()
+	op := p.parseComparisonOp()
+	if op == "" {
+		p.pos = start
+		// test expression
+		switch x := operand.(type) {
+		case *jpQuery:
+			return &jpExistence{query: x}, nil
+		case *jpFunction:
+			if jpFunctions[x.name].result == jpValueType {
+				return nil, p.error(x.name + "() result can't be tested")
+			}
+			return x, nil
+		}
+		return nil, p.error("literal must be compared")
+	}
+	x, err := p.comparable(operand)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	operand, err = p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	y, err := p.comparable(operand)
+	if err != nil {
+		return nil, err
+	}
+	return &jpComparison{op: op, x: x, y: y}, nil
+}
+
+// jpParen keep the parentheses, so that !(@.a == 1) is allowed.
+type jpParen struct {
+	x jpLogical
+}
+
+func (e *jpParen) test(current, root interface{}) bool {
+	return e.x.test(current, root)
+}
+
+func (p *jpParser) parseComparisonOp() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.str[p.pos:], op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// comparable check the operand can be compared, the query must be singular
+// and the function must return ValueType.
+func (p *jpParser) comparable(operand interface{}) (jpComparable, error) {
+	switch x := operand.(type) {
+	case *jpLiteral:
+		return x, nil
+	case *jpQuery:
+		if !x.singular() {
+			return nil, p.error("non-singular query can't be compared")
+		}
+		return &jpSingularQuery{query: x}, nil
+	case *jpFunction:
+		if jpFunctions[x.name].result != jpValueType {
+			return nil, p.error(x.name + "() result can't be compared")
+		}
+		return x, nil
+	}
+	return nil, p.error("invalid comparable")
+}
+
+// parseOperand parse a literal, query or function call.
+func (p *jpParser) parseOperand() (interface{}, error) {
+	switch b := p.peek(); {
+	case b == '@':
+		p.pos++
+		return p.parseSegments(true)
+	case b == '$':
+		p.pos++
+		return p.parseSegments(false)
+	case b == '\'' || b == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jpLiteral{v: s}, nil
+	case b == '-' || isDigit(b):
+		return p.parseNumber()
+	case b >= 'a' && b <= 'z':
+		start := p.pos
+		for p.pos < len(p.str) && (p.str[p.pos] >= 'a' && p.str[p.pos] <= 'z' || p.str[p.pos] == '_' || isDigit(p.str[p.pos])) {
+			p.pos++
+		}
+		name := p.str[start:p.pos]
+		if p.peek() == '(' {
+			return p.parseFunction(name)
+		}
+		switch name {
+		case "true":
+			return &jpLiteral{v: true}, nil
+		case "false":
+			return &jpLiteral{v: false}, nil
+		case "null":
+			return &jpLiteral{v: JSONNull}, nil
+		}
+		p.pos = start
+	}
+	return nil, p.error("invalid expression")
+}
+
+// parseNumber parse the number literal, like JSON number but -0 is allowed.
+func (p *jpParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	p.eat('-')
+	digits := p.pos
+	for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+		p.pos++
+	}
+	if p.pos == digits || (p.str[digits] == '0' && p.pos-digits > 1) {
+		p.pos = start
+		return nil, p.error("invalid number")
+	}
+	if p.eat('.') {
+		frac := p.pos
+		for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+			p.pos++
+		}
+		if p.pos == frac {
+			return nil, p.error("invalid number")
+		}
+	}
+	if p.eat('e') || p.eat('E') {
+		if !p.eat('+') {
+			p.eat('-')
+		}
+		exp := p.pos
+		for p.pos < len(p.str) && isDigit(p.str[p.pos]) {
+			p.pos++
+		}
+		if p.pos == exp {
+			return nil, p.error("invalid number")
+		}
+	}
+	f, err := strconv.ParseFloat(p.str[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.error("invalid number")
+	}
+	return &jpLiteral{v: f}, nil
+}
+
+// parseFunction parse the arguments and check their types.
+func (p *jpParser) parseFunction(name string) (*jpFunction, error) {
+	def, ok := jpFunctions[name]
+	if !ok {
+		return nil, p.error("unknown function " + name + "()")
+	}
+	p.pos++ // (
+	f := &jpFunction{name: name}
+	p.skipSpace()
+	for !p.eat(')') {
+		if len(f.args) > 0 {
+			if !p.eat(',') {
+				return nil, p.error("expected ',' or ')'")
+			}
+			p.skipSpace()
+		}
+		if len(f.args) >= len(def.params) {
+			return nil, p.error("too many arguments of " + name + "()")
+		}
+		arg, err := p.parseArgument(def.params[len(f.args)])
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+		p.skipSpace()
+	}
+	if len(f.args) != len(def.params) {
+		return nil, p.error("too few arguments of " + name + "()")
+	}
+	return f, nil
+}
+
+func (p *jpParser) parseArgument(t jpType) (interface{}, error) {
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case jpNodesType:
+		if q, ok := operand.(*jpQuery); ok {
+			return q, nil
+		}
+		return nil, p.error("argument must be a query")
+	default:
+		return p.comparable(operand)
+	}
+}
@@ -0,0 +1,179 @@
+package xjson
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the example of RFC 9535
+const storeJSON = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+func jsonPathArray(t *testing.T, json, expr string) []interface{} {
+	r, err := JSONPath(json, expr)
+	assert.Nil(t, err, expr)
+	assert.Equal(t, r.Token, Token(ArrayObject))
+	return *(r.object.(*[]interface{}))
+}
+
+func TestJSONPath(t *testing.T) {
+	r, err := JSONPath(storeJSON, "$.store.book[*].author")
+	assert.Nil(t, err)
+	fmt.Println(r)
+	assert.Equal(t, r.Array(), []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"})
+	assert.Equal(t, len(jsonPathArray(t, storeJSON, "$..author")), 4)
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$.store.*")[0].(map[string]interface{})["color"], "red")
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$.store..price"), []interface{}{399, 8.95, 12.99, 8.99, 22.99})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[2].title"), []interface{}{"Moby Dick"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[-1].title"), []interface{}{"The Lord of the Rings"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[0,1].title"), []interface{}{"Sayings of the Century", "Sword of Honour"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[:2].title"), []interface{}{"Sayings of the Century", "Sword of Honour"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[?@.isbn].title"), []interface{}{"Moby Dick", "The Lord of the Rings"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$..book[?@.price<10].title"), []interface{}{"Sayings of the Century", "Moby Dick"})
+	assert.Equal(t, len(jsonPathArray(t, storeJSON, "$..*")), 27)
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$['store']['bicycle'][\"color\"]"), []interface{}{"red"})
+	assert.Equal(t, jsonPathArray(t, storeJSON, "$.nothing"), []interface{}{})
+
+	doc, err := ParseDocument(storeJSON)
+	assert.Nil(t, err)
+	r, err = doc.JSONPath("$.store.bicycle.price")
+	assert.Nil(t, err)
+	assert.Equal(t, r.Array(), []interface{}{399})
+}
+
+func TestJSONPathSelector(t *testing.T) {
+	arr := `["a","b","c","d","e","f","g"]`
+	assert.Equal(t, jsonPathArray(t, arr, "$[1:3]"), []interface{}{"b", "c"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[5:]"), []interface{}{"f", "g"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[1:5:2]"), []interface{}{"b", "d"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[5:1:-2]"), []interface{}{"f", "d"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[::-1]"), []interface{}{"g", "f", "e", "d", "c", "b", "a"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[::0]"), []interface{}{})
+	assert.Equal(t, jsonPathArray(t, arr, "$[ 0 , -1 ]"), []interface{}{"a", "g"})
+	assert.Equal(t, jsonPathArray(t, arr, "$[7]"), []interface{}{})
+	assert.Equal(t, jsonPathArray(t, arr, "$[0, 0]"), []interface{}{"a", "a"})
+
+	obj := `{"o":{"j j":{"k.k":3}},"'":{"@":2},"名字":"bob","a\"b":1}`
+	assert.Equal(t, jsonPathArray(t, obj, "$.o['j j']['k.k']"), []interface{}{3})
+	assert.Equal(t, jsonPathArray(t, obj, `$.o["j j"]["k.k"]`), []interface{}{3})
+	assert.Equal(t, jsonPathArray(t, obj, `$["'"]["@"]`), []interface{}{2})
+	assert.Equal(t, jsonPathArray(t, obj, `$['\'']['@']`), []interface{}{2})
+	assert.Equal(t, jsonPathArray(t, obj, `$.名字`), []interface{}{"bob"})
+	assert.Equal(t, jsonPathArray(t, obj, `$["名字"]`), []interface{}{"bob"})
+	assert.Equal(t, jsonPathArray(t, obj, `$['a"b']`), []interface{}{1})
+	assert.Equal(t, jsonPathArray(t, obj, `$.o[0]`), []interface{}{})
+
+	nested := `{"a":[{"b":1},{"b":[2,{"b":3}]}]}`
+	assert.Equal(t, len(jsonPathArray(t, nested, "$..b")), 3)
+	assert.Equal(t, jsonPathArray(t, nested, "$..[0].b"), []interface{}{1})
+}
+
+func TestJSONPathFilter(t *testing.T) {
+	str := `{"a":[3,5,1,2,4,6,{"b":"j"},{"b":"k"},{"b":{}},{"b":"kilo"}],"o":{"p":1,"q":2,"r":3,"s":5,"t":{"u":6}},"e":"f"}`
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@.b == 'kilo']"), []interface{}{map[string]interface{}{"b": "kilo"}})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?(@.b == 'kilo')]"), []interface{}{map[string]interface{}{"b": "kilo"}})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@>3.5]"), []interface{}{5, 4, 6})
+	assert.Equal(t, len(jsonPathArray(t, str, "$.a[?@.b]")), 4)
+	assert.Equal(t, jsonPathArray(t, str, "$[?@.*]")[0], jsonPathArray(t, str, "$.a")[0])
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@<2 || @.b == \"k\"]"), []interface{}{1, map[string]interface{}{"b": "k"}})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@>1 && @<4]"), []interface{}{3, 2})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?!(@>1 && @<4)][?@ == 'x']"), []interface{}{})
+	assert.Equal(t, jsonPathArray(t, str, "$.o[?@<3, ?@<3]"), []interface{}{1, 2, 1, 2})
+	assert.Equal(t, jsonPathArray(t, str, "$.o[?@>1 && @<4]"), []interface{}{2, 3})
+	assert.Equal(t, jsonPathArray(t, str, "$.o[?@.u || @.x]"), []interface{}{map[string]interface{}{"u": 6}})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@.b == $.x]"), []interface{}{3, 5, 1, 2, 4, 6})
+	assert.Equal(t, jsonPathArray(t, str, "$.a[?@ == @]"), jsonPathArray(t, str, "$.a[*]"))
+}
+
+func TestJSONPathRegexpCache(t *testing.T) {
+	// search 的 ^abc 和 match 的 abc 不能共用缓存
+	str := `[{"a":"abcdef"},{"a":"abc"}]`
+	assert.Equal(t, jsonPathArray(t, str, `$[?search(@.a, '^abc')].a`), []interface{}{"abcdef", "abc"})
+	assert.Equal(t, jsonPathArray(t, str, `$[?match(@.a, 'abc')].a`), []interface{}{"abc"})
+	assert.Equal(t, jsonPathArray(t, str, `$[?search(@.a, '^abc')].a`), []interface{}{"abcdef", "abc"})
+}
+
+func TestRegexpCacheBound(t *testing.T) {
+	// 动态构建的 pattern 不能让缓存无限增长
+	for i := 0; i < maxCachedRegexps*2; i++ {
+		_, err := compileIRegexp(fmt.Sprintf("a%d", i), false)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, cachedRegexps.list.Len(), maxCachedRegexps)
+	assert.Equal(t, len(cachedRegexps.items), maxCachedRegexps)
+
+	cache := newRegexpCache(2)
+	a, b, c := regexpKey{pattern: "a"}, regexpKey{pattern: "b"}, regexpKey{pattern: "c"}
+	cache.add(a, regexp.MustCompile("a"))
+	cache.add(b, regexp.MustCompile("b"))
+	_, ok := cache.get(a)
+	assert.True(t, ok)
+	cache.add(c, regexp.MustCompile("c"))
+	_, ok = cache.get(b)
+	assert.False(t, ok)
+	re, ok := cache.get(a)
+	assert.True(t, ok)
+	assert.Equal(t, re.String(), "a")
+}
+
+func TestJSONPathFunction(t *testing.T) {
+	str := `[{"a":"ab","d":[1,2]},{"a":"bc","d":{"x":1,"y":2,"z":3}},{"a":"b\nc"},{"a":1}]`
+	assert.Equal(t, len(jsonPathArray(t, str, "$[?length(@.a) == 2]")), 2)
+	assert.Equal(t, len(jsonPathArray(t, str, "$[?length(@.d) >= 2]")), 2)
+	assert.Equal(t, len(jsonPathArray(t, str, "$[?count(@.*) == 2]")), 2)
+	assert.Equal(t, len(jsonPathArray(t, str, "$[?count(@..*) > 3]")), 2)
+	assert.Equal(t, jsonPathArray(t, str, "$[?match(@.a, 'a.')].a"), []interface{}{"ab"})
+	assert.Equal(t, jsonPathArray(t, str, "$[?match(@.a, 'b')].a"), []interface{}{})
+	assert.Equal(t, jsonPathArray(t, str, "$[?search(@.a, 'b')].a"), []interface{}{"ab", "bc", "b\nc"})
+	assert.Equal(t, jsonPathArray(t, str, "$[?search(@.a, 'b.c')].a"), []interface{}{})
+	assert.Equal(t, jsonPathArray(t, str, "$[?!match(@.a, '[a-z]+')].a"), []interface{}{"b\nc", 1})
+	assert.Equal(t, jsonPathArray(t, str, "$[?value(@.d.x) == 1].a"), []interface{}{"bc"})
+	assert.Equal(t, jsonPathArray(t, str, "$[?value(@.d.*) == 1].a"), []interface{}{})
+	assert.Equal(t, len(jsonPathArray(t, `{"名字":"张三"}`, "$[?length(@) == 2]")), 1)
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"", "store", " $", "$ ", "$.", "$..", "$.1a", "$[", "$[0", "$['a'", "$[01]", "$[-0]",
+		"$[9007199254740992]", "$[1:2:3:4]", "$['\\\"']", `$["\'"]`, "$[?@.a == ]", "$[?@.* == 1]",
+		"$[?1]", "$[?@.a == 1 == 2]", "$[?length(@.a)]", "$[?match(@.a, 'a') == true]", "$[?count(1) == 1]",
+		"$[?foo(@.a)]", "$[?length(@.a, @.b) == 1]", "$[?!@.a == 1]", "$[?@.a == 01]", "$. a", "$[\"\x01\"]", "$[?@.b == {}]",
+	} {
+		_, err := JSONPath(storeJSON, expr)
+		assert.NotNil(t, err, expr)
+	}
+	_, err := JSONPath(storeJSON, "$[?@.a ==]")
+	fmt.Println(err)
+}
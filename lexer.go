@@ -0,0 +1,211 @@
+package xjson
+
+// Lexer read the tokens of JSON one by one on demand, it doesn't allocate memory for the tokens.
+type Lexer struct {
+	data []byte
+	pos  int
+	// offset is the offset of the last token
+	offset int
+	// buf keep the string which has escape sequences
+	buf []byte
+}
+
+// NewLexer return a Lexer which read tokens from data.
+func NewLexer(data []byte) *Lexer {
+	return &Lexer{data: data}
+}
+
+// NewLexerString is like NewLexer, but read from str.
+func NewLexerString(str string) *Lexer {
+	return NewLexer([]byte(str))
+}
+
+// Offset return the byte offset of the last token.
+func (l *Lexer) Offset() int {
+	return l.offset
+}
+
+// Next return the next token and its value, EndJson is returned at the end of input.
+// The value of a string is unescaped, the value is only valid until the next call.
+func (l *Lexer) Next() (Token, []byte, error) {
+	for l.pos < len(l.data) && isWhitespace(l.data[l.pos]) {
+		l.pos++
+	}
+	l.offset = l.pos
+	if l.pos >= len(l.data) {
+		return EndJson, nil, nil
+	}
+	var t Token
+	switch l.data[l.pos] {
+	case '{':
+		t = BeginObject
+	case '}':
+		t = EndObject
+	case '[':
+		t = BeginArray
+	case ']':
+		t = EndArray
+	case ':':
+		t = SepColon
+	case ',':
+		t = SepComma
+	case '"':
+		return l.readString()
+	case 't':
+		return l.readLiteral("true", True, "invalid bool true")
+	case 'f':
+		return l.readLiteral("false", False, "invalid bool false")
+	case 'n':
+		return l.readLiteral("null", Null, "invalid null")
+	default:
+		if l.data[l.pos] == '-' || isDigit(l.data[l.pos]) {
+			return l.readNumber()
+		}
+		return "", nil, l.error(l.pos, "invalid character")
+	}
+	l.pos++
+	return t, l.data[l.offset:l.pos], nil
+}
+
+func (l *Lexer) error(i int, msg string) error {
+	return newLexError(string(l.data), i, msg)
+}
+
+func (l *Lexer) readLiteral(literal string, t Token, msg string) (Token, []byte, error) {
+	for i := 1; i < len(literal); i++ {
+		p := l.pos + i
+		if p >= len(l.data) {
+			return "", nil, l.error(len(l.data), "unexpected end of JSON input")
+		}
+		if l.data[p] != literal[i] {
+			return "", nil, l.error(p, msg)
+		}
+	}
+	l.pos += len(literal)
+	return t, l.data[l.offset:l.pos], nil
+}
+
+// plainStringByte is the byte which can be copied to the string directly.
+var plainStringByte = func() (table [256]bool) {
+	for b := 0x20; b < 256; b++ {
+		table[b] = b != '"' && b != '\\'
+	}
+	return
+}()
+
+// readString return the slice of data if the string has no escape sequence.
+func (l *Lexer) readString() (Token, []byte, error) {
+	data := l.data
+	start := l.pos + 1
+	i := start
+	for i < len(data) && plainStringByte[data[i]] {
+		i++
+	}
+	if i >= len(data) {
+		return "", nil, l.error(len(data), "unexpected end of JSON input")
+	}
+	switch data[i] {
+	case '"':
+		l.pos = i + 1
+		return String, data[start:i], nil
+	case '\\':
+		l.buf = append(l.buf[:0], data[start:i]...)
+		return l.readEscapedString(i)
+	default:
+		return "", nil, l.error(i, "invalid control character in string")
+	}
+}
+
+// readEscapedString unescape the string from data[i] to buf.
+func (l *Lexer) readEscapedString(i int) (Token, []byte, error) {
+	for ; i < len(l.data); i++ {
+		b := l.data[i]
+		switch {
+		case b == '"':
+			l.pos = i + 1
+			return String, l.buf, nil
+		case b == '\\':
+			i++
+			if i >= len(l.data) {
+				return "", nil, l.error(len(l.data), "unexpected end of JSON input")
+			}
+			var (
+				n   int
+				err error
+			)
+			l.buf, n, err = unescape(l.data, i, l.buf)
+			if err != nil {
+				return "", nil, l.error(i-1, err.Error())
+			}
+			i += n
+		case b < 0x20:
+			return "", nil, l.error(i, "invalid control character in string")
+		default:
+			l.buf = append(l.buf, b)
+		}
+	}
+	return "", nil, l.error(len(l.data), "unexpected end of JSON input")
+}
+
+// readNumber read -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (l *Lexer) readNumber() (Token, []byte, error) {
+	data := l.data
+	i := l.pos
+	t := Token(Number)
+	if data[i] == '-' {
+		i++
+		if i >= len(data) {
+			return "", nil, l.error(i, "invalid number")
+		}
+		if !isDigit(data[i]) {
+			return "", nil, l.error(i, "invalid number")
+		}
+	}
+	if data[i] == '0' {
+		i++
+		if i < len(data) && isDigit(data[i]) {
+			return "", nil, l.error(i, "invalid number, leading zero")
+		}
+	} else {
+		for i < len(data) && isDigit(data[i]) {
+			i++
+		}
+	}
+
+	if i < len(data) && data[i] == '.' {
+		t = Float
+		i++
+		if i >= len(data) {
+			return "", nil, l.error(i, "invalid number")
+		}
+		if !isDigit(data[i]) {
+			return "", nil, l.error(i, "invalid float")
+		}
+		for i < len(data) && isDigit(data[i]) {
+			i++
+		}
+		if i < len(data) && data[i] == '.' {
+			return "", nil, l.error(i, "invalid float")
+		}
+	}
+
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		// 1e10 也是浮点数
+		t = Float
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		if i >= len(data) {
+			return "", nil, l.error(i, "invalid number")
+		}
+		if !isDigit(data[i]) {
+			return "", nil, l.error(i, "invalid exponent")
+		}
+		for i < len(data) && isDigit(data[i]) {
+			i++
+		}
+	}
+	l.pos = i
+	return t, data[l.offset:l.pos], nil
+}
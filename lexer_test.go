@@ -0,0 +1,142 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLexerNext(t *testing.T) {
+	lexer := NewLexerString(`{"name":"cj", "age":-1.5e3, "list":[true,false,null,10]}`)
+	var tokens []Token
+	var values []string
+	for {
+		token, value, err := lexer.Next()
+		assert.Nil(t, err)
+		if token == EndJson {
+			break
+		}
+		fmt.Printf("%s  %s  %d\n", token, value, lexer.Offset())
+		tokens = append(tokens, token)
+		values = append(values, string(value))
+	}
+	assert.Equal(t, tokens, []Token{BeginObject, String, SepColon, String, SepComma, String, SepColon, Float, SepComma,
+		String, SepColon, BeginArray, True, SepComma, False, SepComma, Null, SepComma, Number, EndArray, EndObject})
+	assert.Equal(t, values[1], "name")
+	assert.Equal(t, values[7], "-1.5e3")
+	assert.Equal(t, values[18], "10")
+}
+
+func TestLexerOffset(t *testing.T) {
+	lexer := NewLexerString(` [ "a" ,1]`)
+	var offsets []int
+	for {
+		token, _, err := lexer.Next()
+		assert.Nil(t, err)
+		if token == EndJson {
+			break
+		}
+		offsets = append(offsets, lexer.Offset())
+	}
+	assert.Equal(t, offsets, []int{1, 3, 7, 8, 9})
+}
+
+func TestLexerEscape(t *testing.T) {
+	lexer := NewLexerString(`["a\"b\n", "中😀", "c"]`)
+	var values []string
+	for {
+		token, value, err := lexer.Next()
+		assert.Nil(t, err)
+		if token == EndJson {
+			break
+		}
+		if token == String {
+			values = append(values, string(value))
+		}
+	}
+	assert.Equal(t, values, []string{"a\"b\n", "中😀", "c"})
+}
+
+func TestLexerError(t *testing.T) {
+	cases := []struct {
+		json   string
+		offset int
+		msg    string
+	}{
+		{`[1, x]`, 4, "invalid character"},
+		{`"a\x"`, 2, "invalid escape '\\x'"},
+		{`"abc`, 4, "unexpected end of JSON input"},
+		{`01`, 1, "invalid number, leading zero"},
+		{`1.a`, 2, "invalid float"},
+		{`1e+`, 3, "invalid number"},
+		{`nul`, 3, "unexpected end of JSON input"},
+		{`trux`, 3, "invalid bool true"},
+	}
+	for _, c := range cases {
+		lexer := NewLexerString(c.json)
+		var err error
+		for err == nil {
+			var token Token
+			token, _, err = lexer.Next()
+			if token == EndJson {
+				break
+			}
+		}
+		e, ok := err.(*SyntaxError)
+		assert.True(t, ok, c.json)
+		fmt.Println(err)
+		assert.Equal(t, e.Offset, c.offset, c.json)
+		assert.Contains(t, e.Error(), c.msg, c.json)
+	}
+}
+
+func TestParseLexer(t *testing.T) {
+	decode, err := ParseLexer(NewLexerString(`{"name":"cj","list":[1,2.5,null]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, decode, map[string]interface{}{"name": "cj", "list": &[]interface{}{1, 2.5, JSONNull}})
+
+	decode, err = ParseLexerWithNumberMode(NewLexerString(`[10]`), NumberModeRaw)
+	assert.Nil(t, err)
+	assert.Equal(t, decode, &[]interface{}{RawNumber("10")})
+
+	_, err = ParseLexer(NewLexerString(`{"a":1,}`))
+	assert.NotNil(t, err)
+	fmt.Println(err)
+}
+
+func TestLexerAllocs(t *testing.T) {
+	data := []byte(`{"name":"cj", "age":10, "list":[1.5,true,false,null,"a\nb"]}`)
+	lexer := NewLexer(data)
+	allocs := testing.AllocsPerRun(100, func() {
+		*lexer = Lexer{data: data, buf: lexer.buf}
+		for {
+			token, _, err := lexer.Next()
+			if err != nil || token == EndJson {
+				return
+			}
+		}
+	})
+	assert.Equal(t, allocs, float64(0))
+}
+
+func BenchmarkLexer(b *testing.B) {
+	data := []byte(`{"name":"cj", "age":10, "object":{"a":"a\tb","b":[1,2.5,true,null]}}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lexer := NewLexer(data)
+		for {
+			token, _, err := lexer.Next()
+			if err != nil || token == EndJson {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkTokenize(b *testing.B) {
+	str := `{"name":"cj", "age":10, "object":{"a":"a\tb","b":[1,2.5,true,null]}}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Tokenize(str)
+	}
+}
@@ -0,0 +1,432 @@
+package xjson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Marshaler is the interface implemented by types that can marshal themselves into valid JSON,
+// it is the same as json.Marshaler.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Marshal return the JSON encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encoder{}
+	if err := e.marshal(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// MarshalIndent is like Marshal but each JSON element begins on a new line
+// beginning with prefix followed by one or more copies of indent.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	e := &encoder{prefix: prefix, indent: indent}
+	if err := e.marshal(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// UnsupportedValueError is returned by Marshal when the value can't be encoded, such as a cyclic structure.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	return "unsupported value: " + e.Str
+}
+
+// startDetectingCyclesAfter is the nesting level of pointers/maps/slices to start detecting the cycle,
+// the most values are not deep so the check is skipped like encoding/json does.
+const startDetectingCyclesAfter = 1000
+
+type encoder struct {
+	bytes.Buffer
+	prefix string
+	indent string
+	depth  int
+	// ptrLevel is the nesting level of pointers/maps/slices, ptrSeen keep those being encoded
+	ptrLevel int
+	ptrSeen  map[ptrKey]struct{}
+}
+
+type ptrKey struct {
+	ptr uintptr
+	len int
+}
+
+// enter record the pointer/map/slice v before encoding it, an error is returned when v is already being encoded.
+func (e *encoder) enter(v reflect.Value) error {
+	e.ptrLevel++
+	if e.ptrLevel <= startDetectingCyclesAfter {
+		return nil
+	}
+	key := pointerKey(v)
+	if _, ok := e.ptrSeen[key]; ok {
+		return &UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+	}
+	if e.ptrSeen == nil {
+		e.ptrSeen = map[ptrKey]struct{}{}
+	}
+	e.ptrSeen[key] = struct{}{}
+	return nil
+}
+
+func (e *encoder) leave(v reflect.Value) {
+	if e.ptrLevel > startDetectingCyclesAfter {
+		delete(e.ptrSeen, pointerKey(v))
+	}
+	e.ptrLevel--
+}
+
+func pointerKey(v reflect.Value) ptrKey {
+	key := ptrKey{ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		// 同一个底层数组的不同切片不是循环
+		key.len = v.Len()
+	}
+	return key
+}
+
+func (e *encoder) marshal(v reflect.Value) error {
+	if !v.IsValid() {
+		e.WriteString("null")
+		return nil
+	}
+
+	if v.Type().Implements(marshalerType) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		b, err := v.Interface().(Marshaler).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		e.Write(b)
+		return nil
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && (reflect.PtrTo(v.Type()).Implements(marshalerType) ||
+		reflect.PtrTo(v.Type()).Implements(textMarshalerType)) {
+		return e.marshal(v.Addr())
+	}
+	if v.Type().Implements(textMarshalerType) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		writeString(&e.Buffer, string(b))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		s, err := formatFloat(v.Float(), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		e.WriteString(s)
+	case reflect.String:
+		writeString(&e.Buffer, v.String())
+	case reflect.Interface:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		return e.marshal(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+		return e.marshal(v.Elem())
+	case reflect.Struct:
+		return e.marshalStruct(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+		return e.marshalMap(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			e.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte 和 encoding/json 一样使用 base64 编码
+			e.WriteByte('"')
+			e.WriteString(base64.StdEncoding.EncodeToString(v.Bytes()))
+			e.WriteByte('"')
+			return nil
+		}
+		if err := e.enter(v); err != nil {
+			return err
+		}
+		defer e.leave(v)
+		return e.marshalArray(v)
+	case reflect.Array:
+		return e.marshalArray(v)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *encoder) marshalStruct(v reflect.Value) error {
+	e.WriteByte('{')
+	e.depth++
+	count := 0
+	for _, f := range cachedStructFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if count > 0 {
+			e.WriteByte(',')
+		}
+		e.writeKey(f.name)
+		if err := e.marshal(fv); err != nil {
+			return err
+		}
+		count++
+	}
+	e.depth--
+	if count > 0 {
+		e.newline()
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+func (e *encoder) marshalMap(v reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	e.WriteByte('{')
+	e.depth++
+	for i, en := range entries {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		e.writeKey(en.key)
+		if err := e.marshal(en.value); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	if len(entries) > 0 {
+		e.newline()
+	}
+	e.WriteByte('}')
+	return nil
+}
+
+func (e *encoder) marshalArray(v reflect.Value) error {
+	e.WriteByte('[')
+	e.depth++
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		e.newline()
+		if err := e.marshal(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	e.depth--
+	if v.Len() > 0 {
+		e.newline()
+	}
+	e.WriteByte(']')
+	return nil
+}
+
+func (e *encoder) writeKey(key string) {
+	e.newline()
+	writeString(&e.Buffer, key)
+	e.WriteByte(':')
+	if e.indent != "" || e.prefix != "" {
+		e.WriteByte(' ')
+	}
+}
+
+func (e *encoder) newline() {
+	if e.indent == "" && e.prefix == "" {
+		return
+	}
+	e.WriteByte('\n')
+	e.WriteString(e.prefix)
+	for i := 0; i < e.depth; i++ {
+		e.WriteString(e.indent)
+	}
+}
+
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+// formatFloat format float like encoding/json, NaN and Inf are not supported by JSON.
+func formatFloat(f float64, bits int) (string, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", errors.New("unsupported float value " + strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	s := strconv.FormatFloat(f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s, nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+const hex = "0123456789abcdef"
+
+// writeString write s as a quoted JSON string, escaping quotes, backslash and control characters.
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[b>>4])
+				buf.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			// U+2028 and U+2029 are invalid in JavaScript strings
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
@@ -0,0 +1,103 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	p := Person{
+		Name:    "bob",
+		Height:  1.8,
+		Skills:  []string{"go", "java"},
+		Address: &Address{City: "cd"},
+		Tags:    map[string]string{"b": "b", "a": "a"},
+		Ignore:  "ignore",
+		NoTag:   1,
+	}
+	marshal, err := Marshal(p)
+	assert.Nil(t, err)
+	fmt.Println(string(marshal))
+	assert.Equal(t, string(marshal), `{"name":"bob","height":1.8,"married":false,"skills":["go","java"],"address":{"city":"cd"},"tags":{"a":"a","b":"b"},"extra":null,"NoTag":1}`)
+
+	var p2 Person
+	err = Unmarshal(string(marshal), &p2)
+	assert.Nil(t, err)
+	assert.Equal(t, p2.Tags, p.Tags)
+	assert.Equal(t, p2.Address.City, "cd")
+}
+
+func TestMarshal2(t *testing.T) {
+	marshal, err := Marshal(map[int]interface{}{2: []byte("hi"), 1: nil, 3: 1e21, 4: float32(0.1)})
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"1":null,"2":"aGk=","3":1e+21,"4":0.1}`)
+
+	marshal, err = Marshal("a\"b\\c\n\t\u0001<\u2028")
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `"a\"b\\c\n\t\u0001<\u2028"`)
+
+	date := time.Date(2022, 7, 1, 8, 0, 0, 0, time.UTC)
+	marshal, err = Marshal(struct {
+		Date time.Time  `json:"date"`
+		Ptr  *time.Time `json:"ptr,omitempty"`
+	}{Date: date})
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"date":"2022-07-01T08:00:00Z"}`)
+
+	decode, err := Decode(`{"a":[1,2.5,true]}`)
+	assert.Nil(t, err)
+	marshal, err = Marshal(decode)
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"a":[1,2.5,true]}`)
+}
+
+func TestMarshalIndent(t *testing.T) {
+	indent, err := MarshalIndent(map[string]interface{}{"a": []int{1, 2}, "b": map[string]int{}, "c": []int{}}, "", "\t")
+	assert.Nil(t, err)
+	fmt.Println(string(indent))
+	assert.Equal(t, string(indent), "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t],\n\t\"b\": {},\n\t\"c\": []\n}")
+}
+
+func TestMarshalErr(t *testing.T) {
+	_, err := Marshal(make(chan int))
+	assert.NotNil(t, err)
+	fmt.Println(err)
+
+	_, err = Marshal([]float64{1, 0, -1}[0] / 0)
+	assert.NotNil(t, err)
+}
+
+type Cyc struct {
+	N *Cyc `json:"n"`
+}
+
+func TestMarshalCycle(t *testing.T) {
+	c := &Cyc{}
+	c.N = c
+	_, err := Marshal(c)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+	_, ok := err.(*UnsupportedValueError)
+	assert.True(t, ok)
+
+	m := map[string]interface{}{}
+	m["m"] = m
+	_, err = Marshal(m)
+	assert.NotNil(t, err)
+
+	s := []interface{}{nil}
+	s[0] = s
+	_, err = Marshal(s)
+	assert.NotNil(t, err)
+
+	_, err = Set(`{"a":1}`, "a", c)
+	assert.NotNil(t, err)
+
+	// 共享但不循环的值可以编码
+	a := &Address{City: "cd"}
+	marshal, err := Marshal([]*Address{a, a})
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `[{"city":"cd"},{"city":"cd"}]`)
+}
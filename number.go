@@ -0,0 +1,140 @@
+package xjson
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RawNumber keep the original literal of a JSON number, like json.Number.
+type RawNumber string
+
+// String return the literal of the number.
+func (n RawNumber) String() string {
+	return string(n)
+}
+
+// Int64 return the number as an int64.
+func (n RawNumber) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Uint64 return the number as an uint64.
+func (n RawNumber) Uint64() (uint64, error) {
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+// Float64 return the number as a float64.
+func (n RawNumber) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// BigInt return the number as a *big.Int.
+func (n RawNumber) BigInt() (*big.Int, error) {
+	i, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, errors.New("invalid big int '" + string(n) + "'")
+	}
+	return i, nil
+}
+
+// BigFloat return the number as a *big.Float.
+func (n RawNumber) BigFloat() (*big.Float, error) {
+	f, _, err := big.ParseFloat(string(n), 10, 0, big.ToNearestEven)
+	return f, err
+}
+
+// IsInteger check if the literal has no fraction and exponent.
+func (n RawNumber) IsInteger() bool {
+	return !strings.ContainsAny(string(n), ".eE")
+}
+
+// MarshalJSON write the literal as it is.
+func (n RawNumber) MarshalJSON() ([]byte, error) {
+	if n == "" {
+		return []byte("0"), nil
+	}
+	return []byte(n), nil
+}
+
+// NumberMode decide which Go type a JSON number is decoded to,
+// the number which doesn't fit the type is kept as RawNumber.
+type NumberMode int
+
+const (
+	// NumberModeDefault decode integers to int and others to float64,
+	// the number which overflow is kept as RawNumber.
+	NumberModeDefault NumberMode = iota
+	// NumberModeRaw decode all numbers to RawNumber.
+	NumberModeRaw
+	// NumberModeInt64 decode integers to int64 and others to float64.
+	NumberModeInt64
+	// NumberModeUint64 decode integers to uint64 and others to float64.
+	NumberModeUint64
+	// NumberModeFloat64 decode all numbers to float64.
+	NumberModeFloat64
+	// NumberModeBigInt decode integers to *big.Int and others to *big.Float.
+	NumberModeBigInt
+	// NumberModeBigFloat decode all numbers to *big.Float.
+	NumberModeBigFloat
+)
+
+// parseNumber covert the Number/Float token to Go value by mode, the number which doesn't fit
+// the type of mode (e.g. -1 for uint64) is kept as RawNumber.
+func parseNumber(t Token, value []byte, mode NumberMode) interface{} {
+	n := RawNumber(value)
+	isInteger := t == Number
+	var (
+		v   interface{}
+		err error
+	)
+	switch mode {
+	case NumberModeRaw:
+		return n
+	case NumberModeInt64:
+		if isInteger {
+			v, err = n.Int64()
+		} else {
+			v, err = n.Float64()
+		}
+	case NumberModeUint64:
+		if isInteger {
+			v, err = n.Uint64()
+		} else {
+			v, err = n.Float64()
+		}
+	case NumberModeFloat64:
+		v, err = n.Float64()
+	case NumberModeBigInt:
+		if isInteger {
+			v, err = n.BigInt()
+		} else {
+			v, err = n.BigFloat()
+		}
+	case NumberModeBigFloat:
+		v, err = n.BigFloat()
+	default:
+		return n.defaultValue()
+	}
+	if err != nil {
+		return n
+	}
+	return v
+}
+
+// defaultValue covert n to int or float64, keep RawNumber when it overflow.
+func (n RawNumber) defaultValue() interface{} {
+	if n.IsInteger() {
+		i, err := strconv.Atoi(string(n))
+		if err != nil {
+			return n
+		}
+		return i
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return n
+	}
+	return f
+}
@@ -0,0 +1,99 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestNumberToken(t *testing.T) {
+	str := `{"a":-5,"b":1e10,"c":-1.5E-3,"d":0,"e":2e+2}`
+	tokenize, err := Tokenize(str)
+	assert.Nil(t, err)
+	for _, tokenType := range tokenize {
+		fmt.Printf("%s  %s\n", tokenType.T, tokenType.Value)
+	}
+	assert.Equal(t, tokenize[3].T, Token(Number))
+	assert.Equal(t, tokenize[3].Value, "-5")
+	assert.Equal(t, tokenize[7].T, Token(Float))
+	assert.Equal(t, tokenize[7].Value, "1e10")
+	assert.Equal(t, tokenize[11].T, Token(Float))
+	assert.Equal(t, tokenize[11].Value, "-1.5E-3")
+	assert.Equal(t, tokenize[15].Value, "0")
+	assert.Equal(t, tokenize[19].Value, "2e+2")
+
+	for _, s := range []string{`{"a":-}`, `{"a":01}`, `{"a":1.}`, `{"a":1e}`, `{"a":1e+}`, `{"a":--1}`} {
+		_, err = Decode(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestDecodeNumber(t *testing.T) {
+	str := `{"a":-5,"b":1e10,"c":12345678901234567890,"d":-1.5e-3}`
+	decode, err := Decode(str)
+	assert.Nil(t, err)
+	v := decode.(map[string]interface{})
+	assert.Equal(t, v["a"], -5)
+	assert.Equal(t, v["b"], 1e10)
+	assert.Equal(t, v["c"], RawNumber("12345678901234567890"))
+	assert.Equal(t, v["d"], -1.5e-3)
+
+	assert.Equal(t, Get(str, "a").Int(), -5)
+	assert.Equal(t, Get(str, "c").String(), "12345678901234567890")
+	assert.Equal(t, Get(str, "b").Float(), 1e10)
+
+	decode, err = DecodeWithNumberMode(str, NumberModeRaw)
+	assert.Nil(t, err)
+	v = decode.(map[string]interface{})
+	assert.Equal(t, v["b"], RawNumber("1e10"))
+	f, err := v["d"].(RawNumber).Float64()
+	assert.Nil(t, err)
+	assert.Equal(t, f, -1.5e-3)
+
+	decode, err = DecodeWithNumberMode(`[12345678901234567890,1.5]`, NumberModeUint64)
+	assert.Nil(t, err)
+	assert.Equal(t, (*decode.(*[]interface{}))[0], uint64(12345678901234567890))
+	assert.Equal(t, (*decode.(*[]interface{}))[1], 1.5)
+
+	// 放不下的数字保留为 RawNumber
+	decode, err = DecodeWithNumberMode(`[12345678901234567890,1]`, NumberModeInt64)
+	assert.Nil(t, err)
+	assert.Equal(t, (*decode.(*[]interface{}))[0], RawNumber("12345678901234567890"))
+	assert.Equal(t, (*decode.(*[]interface{}))[1], int64(1))
+	decode, err = DecodeWithNumberMode(`{"a":-1}`, NumberModeUint64)
+	assert.Nil(t, err)
+	assert.Equal(t, decode.(map[string]interface{})["a"], RawNumber("-1"))
+	decode, err = DecodeWithNumberMode(`[1e400]`, NumberModeFloat64)
+	assert.Nil(t, err)
+	assert.Equal(t, (*decode.(*[]interface{}))[0], RawNumber("1e400"))
+
+	decode, err = DecodeWithNumberMode(`[123456789012345678901234567890,0.1]`, NumberModeBigInt)
+	assert.Nil(t, err)
+	i, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assert.Equal(t, (*decode.(*[]interface{}))[0], i)
+	assert.Equal(t, (*decode.(*[]interface{}))[1].(*big.Float).Text('g', -1), "0.1")
+
+	decode, err = DecodeWithNumberMode(`[1]`, NumberModeFloat64)
+	assert.Nil(t, err)
+	assert.Equal(t, (*decode.(*[]interface{}))[0], 1.0)
+}
+
+func TestUnmarshalNumber(t *testing.T) {
+	var v struct {
+		ID    uint64    `json:"id"`
+		Raw   RawNumber `json:"raw"`
+		Big   *big.Int  `json:"big"`
+		Money big.Float `json:"money"`
+	}
+	err := Unmarshal(`{"id":18446744073709551615,"raw":1.50,"big":123456789012345678901234567890,"money":19.99}`, &v)
+	assert.Nil(t, err)
+	assert.Equal(t, v.ID, uint64(18446744073709551615))
+	assert.Equal(t, v.Raw, RawNumber("1.50"))
+	assert.Equal(t, v.Big.String(), "123456789012345678901234567890")
+	assert.Equal(t, v.Money.Text('f', 2), "19.99")
+
+	marshal, err := Marshal(&v)
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"id":18446744073709551615,"raw":1.50,"big":123456789012345678901234567890,"money":"19.99"}`)
+}
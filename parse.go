@@ -1,13 +1,9 @@
 package xjson
 
-import (
-	"errors"
-	"strconv"
-)
-
 type status int
 
 const (
+	StatusEnd         status = 0x0001 // EOF
 	StatusObjectKey   status = 0x0002
 	StatusColon       status = 0x0004 //;
 	StatusObjectValue status = 0x0008
@@ -17,25 +13,54 @@ const (
 	StatusBeginArray  status = 0x0080
 	StatusEndArray    status = 0x0100
 	StatusArrayValue  status = 0x0200
+	StatusRootValue   status = 0x0400 // "abc", 1, true, null
 )
 
+// tokenSource is where the parser pull tokens from, it is a Lexer or a TokenReader.
+type tokenSource interface {
+	Next() (Token, []byte, error)
+	Offset() int
+}
+
 func Parse(reader *TokenReader) (interface{}, error) {
+	return ParseWithNumberMode(reader, NumberModeDefault)
+}
+
+// ParseWithNumberMode is like Parse but decode numbers by mode.
+func ParseWithNumberMode(reader *TokenReader, mode NumberMode) (interface{}, error) {
+	return parse(reader, mode)
+}
+
+// ParseLexer parse the tokens read from lexer on demand, like Parse.
+func ParseLexer(lexer *Lexer) (interface{}, error) {
+	return parse(lexer, NumberModeDefault)
+}
+
+// ParseLexerWithNumberMode is like ParseLexer but decode numbers by mode.
+func ParseLexerWithNumberMode(lexer *Lexer, mode NumberMode) (interface{}, error) {
+	return parse(lexer, mode)
+}
+
+func parse(source tokenSource, mode NumberMode) (interface{}, error) {
 	s := &Stack{}
-	status := StatusBeginObject | StatusBeginArray
+	status := StatusBeginObject | StatusBeginArray | StatusRootValue
 	for {
-		tokenType := reader.Read()
-		switch tokenType.T {
+		t, value, err := source.Next()
+		if err != nil {
+			return nil, err
+		}
+		switch t {
 		case BeginObject:
 			if !includeTokenStatus(StatusBeginObject, status) {
-				return nil, errors.New("invalid '{'")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			}
 			root := make(map[string]interface{})
 			stackValue := NewObjectValue(root)
 			s.Push(stackValue)
-			status = StatusObjectKey | StatusBeginObject | StatusEndObject
+			status = StatusObjectKey | StatusEndObject
 		case String:
 			if includeTokenStatus(StatusObjectKey, status) {
-				stackValue := NewObjectKey(tokenType.Value)
+				stackValue := NewObjectKey(string(value))
 				s.Push(stackValue)
 				status = StatusColon
 				continue
@@ -44,114 +69,111 @@ func Parse(reader *TokenReader) (interface{}, error) {
 				// ObjectValue 后跟的可能是 , 和 }
 				objectKey := s.Pop().ObjectKeyValue()
 				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = tokenType.Value
+				rootMap[objectKey] = string(value)
 				status = StatusComma | StatusEndObject
 				continue
 			}
 			if includeTokenStatus(StatusArrayValue, status) {
 				arrayValue := s.Peek().ArrayValuePoint()
-				*arrayValue = append(*arrayValue, tokenType.Value)
+				*arrayValue = append(*arrayValue, string(value))
 				status = StatusComma | StatusEndArray
 				continue
 			}
-			return nil, errors.New("invalid string '" + tokenType.Value + "'")
+			if includeTokenStatus(StatusRootValue, status) {
+				s.Push(NewValue(string(value)))
+				status = StatusEnd
+				continue
+			}
+			return nil, newParseError(sourceToken(source, t, value), status)
 
-		case Number:
-			// todo crossoverJie 优雅转为整形
+		case Number, Float:
 			if includeTokenStatus(StatusObjectValue, status) {
-				i, _ := strconv.Atoi(tokenType.Value)
+				n := parseNumber(t, value, mode)
 				objectKey := s.Pop().ObjectKeyValue()
 				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = i
+				rootMap[objectKey] = n
 				status = StatusComma | StatusEndObject
 				continue
 			}
 			if includeTokenStatus(StatusArrayValue, status) {
-				i, _ := strconv.Atoi(tokenType.Value)
+				n := parseNumber(t, value, mode)
 				arrayValue := s.Peek().ArrayValuePoint()
-				//arrayValue := s.Pop().ArrayValue()
-				*arrayValue = append(*arrayValue, i)
-				//s.Push(NewArray(arrayValue))
+				*arrayValue = append(*arrayValue, n)
 				status = StatusComma | StatusEndArray
 				continue
 			}
-			return nil, errors.New("invalid int")
-		case Float:
-			// todo crossoverJie 优雅转为整形
-			if includeTokenStatus(StatusObjectValue, status) {
-				i, _ := strconv.ParseFloat(tokenType.Value, 64)
-
-				objectKey := s.Pop().ObjectKeyValue()
-				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = i
-				status = StatusComma | StatusEndObject
-				continue
-			}
-			if includeTokenStatus(StatusArrayValue, status) {
-				i, _ := strconv.ParseFloat(tokenType.Value, 64)
-
-				arrayValue := s.Peek().ArrayValuePoint()
-				*arrayValue = append(*arrayValue, i)
-				status = StatusComma | StatusEndArray
+			if includeTokenStatus(StatusRootValue, status) {
+				n := parseNumber(t, value, mode)
+				s.Push(NewValue(n))
+				status = StatusEnd
 				continue
 			}
-			return nil, errors.New("invalid int")
+			return nil, newParseError(sourceToken(source, t, value), status)
 		case True:
-			value := tokenType.Value
 			if includeTokenStatus(StatusObjectValue, status) {
-				b, _ := strconv.ParseBool(value)
 				objectKey := s.Pop().ObjectKeyValue()
 				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = b
+				rootMap[objectKey] = true
 				status = StatusComma | StatusEndObject
 				continue
 			}
 			if includeTokenStatus(StatusArrayValue, status) {
-				b, _ := strconv.ParseBool(value)
 				arrayValue := s.Peek().ArrayValuePoint()
-				*arrayValue = append(*arrayValue, b)
+				*arrayValue = append(*arrayValue, true)
 				status = StatusComma | StatusEndArray
 				continue
 			}
-			return nil, errors.New("invalid bool true")
+			if includeTokenStatus(StatusRootValue, status) {
+				s.Push(NewValue(true))
+				status = StatusEnd
+				continue
+			}
+			return nil, newParseError(sourceToken(source, t, value), status)
 		case False:
-			value := tokenType.Value
 			if includeTokenStatus(StatusObjectValue, status) {
-				b, _ := strconv.ParseBool(value)
 				objectKey := s.Pop().ObjectKeyValue()
 				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = b
+				rootMap[objectKey] = false
 				status = StatusComma | StatusEndObject
 				continue
 			}
 			if includeTokenStatus(StatusArrayValue, status) {
-				b, _ := strconv.ParseBool(value)
 				arrayValue := s.Peek().ArrayValuePoint()
-				*arrayValue = append(*arrayValue, b)
+				*arrayValue = append(*arrayValue, false)
 				status = StatusComma | StatusEndArray
 				continue
 			}
-			return nil, errors.New("invalid bool false")
+			if includeTokenStatus(StatusRootValue, status) {
+				s.Push(NewValue(false))
+				status = StatusEnd
+				continue
+			}
+			return nil, newParseError(sourceToken(source, t, value), status)
 		case Null:
 			if includeTokenStatus(StatusObjectValue, status) {
 				objectKey := s.Pop().ObjectKeyValue()
 				rootMap := s.Peek().ObjectValue()
-				rootMap[objectKey] = ""
+				rootMap[objectKey] = JSONNull
 				status = StatusComma | StatusEndObject
 				continue
 			}
 			if includeTokenStatus(StatusArrayValue, status) {
 				arrayValue := s.Peek().ArrayValuePoint()
-				*arrayValue = append(*arrayValue, "")
+				*arrayValue = append(*arrayValue, JSONNull)
 				status = StatusComma | StatusEndArray
 				continue
 			}
-			return nil, errors.New("invalid null")
+			if includeTokenStatus(StatusRootValue, status) {
+				s.Push(NewValue(JSONNull))
+				status = StatusEnd
+				continue
+			}
+			return nil, newParseError(sourceToken(source, t, value), status)
 
 		case SepComma:
 			//,
 			if !includeTokenStatus(StatusComma, status) {
-				return nil, errors.New("missing ':'")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			} else {
 				// 逗号之前可能是 '}',下一个状态则是 StatusObjectKey
 				if includeTokenStatus(StatusEndObject, status) {
@@ -168,7 +190,7 @@ func Parse(reader *TokenReader) (interface{}, error) {
 		case SepColon:
 			//:
 			if !includeTokenStatus(StatusColon, status) {
-				return nil, errors.New("missing ','")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			} else {
 				status = StatusObjectValue | StatusBeginObject | StatusBeginArray
 			}
@@ -180,14 +202,16 @@ func Parse(reader *TokenReader) (interface{}, error) {
 				status = StatusArrayValue | StatusBeginArray | StatusBeginObject | StatusEndArray
 				continue
 			}
+			return nil, newParseError(sourceToken(source, t, value), status)
 		case EndArray:
 			if !includeTokenStatus(StatusEndArray, status) {
-				return nil, errors.New("invalid ']'")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			}
 			root := s.Pop().ArrayValuePoint()
 			if s.IsEmpty() {
 				array := NewArrayPoint(root)
 				s.Push(array)
+				status = StatusEnd
 				continue
 			}
 
@@ -209,17 +233,18 @@ func Parse(reader *TokenReader) (interface{}, error) {
 				continue
 			}
 
-			return nil, errors.New("miss ']'")
+			return nil, newParseError(sourceToken(source, t, value), status)
 
 		case EndObject:
 			if !includeTokenStatus(StatusEndObject, status) {
-				return nil, errors.New("invalid '}'")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			}
 			root := s.Pop().ObjectValue()
 			if s.IsEmpty() {
 				value := NewObjectValue(root)
 				s.Push(value)
 				// 此时栈已经读完，表名所有 token 解析完毕
+				status = StatusEnd
 				continue
 			}
 
@@ -244,13 +269,17 @@ func Parse(reader *TokenReader) (interface{}, error) {
 
 		case EndJson:
 			// token 读不到数据了，EOF
+			if !includeTokenStatus(StatusEnd, status) {
+				return nil, newParseError(sourceToken(source, t, value), status)
+			}
 			root := s.Pop().Raw()
 			if s.IsEmpty() {
 				return root, nil
 			} else {
-				return nil, errors.New("invalid EOF")
+				return nil, newParseError(sourceToken(source, t, value), status)
 			}
-
+		default:
+			return nil, newParseError(sourceToken(source, t, value), status)
 		}
 	}
 }
@@ -258,3 +287,8 @@ func Parse(reader *TokenReader) (interface{}, error) {
 func includeTokenStatus(current, target status) bool {
 	return (current & target) > 0
 }
+
+// sourceToken build the TokenType of the current token for the error.
+func sourceToken(source tokenSource, t Token, value []byte) *TokenType {
+	return &TokenType{T: t, Value: string(value), Offset: source.Offset()}
+}
@@ -0,0 +1,269 @@
+package xjson
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// patchOperation is an operation of RFC 6902 JSON Patch.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	From  string      `json:"from,omitempty"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ApplyPatch apply the RFC 6902 JSON Patch to doc and return the patched json, the operations
+// add, remove, replace, move, copy and test are supported. The patch is atomic,
+// nothing is returned when any operation fails.
+func ApplyPatch(doc, patch string) (string, error) {
+	root, err := DecodeWithNumberMode(doc, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	p, err := DecodeWithNumberMode(patch, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	operations, ok := p.(*[]interface{})
+	if !ok {
+		return "", errors.New("patch must be an array")
+	}
+	for i, operation := range *operations {
+		root, err = applyOperation(root, operation)
+		if err != nil {
+			return "", fmt.Errorf("patch operation %d: %w", i, err)
+		}
+	}
+	data, err := Marshal(root)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// applyOperation apply the operation to root and return the new root.
+func applyOperation(root, operation interface{}) (interface{}, error) {
+	m, ok := operation.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("operation must be an object")
+	}
+	op, err := operationMember(m, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := operationMember(m, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add", "replace", "test":
+		value, ok := m["value"]
+		if !ok {
+			return nil, fmt.Errorf("'%s' must have value", op)
+		}
+		switch op {
+		case "add":
+			return setPointer(root, path, value, pointerAdd)
+		case "replace":
+			if _, err := getPointer(root, path); err != nil {
+				return nil, err
+			}
+			return setPointer(root, path, value, pointerReplace)
+		default:
+			r, err := getPointer(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !equalValues(r.object, value) {
+				return nil, fmt.Errorf("test '%s' failed", path)
+			}
+			return root, nil
+		}
+	case "remove":
+		if path == "" {
+			return nil, errors.New("can't remove the root")
+		}
+		_, err := removePointer(root, path)
+		return root, err
+	case "move", "copy":
+		from, err := operationMember(m, "from")
+		if err != nil {
+			return nil, err
+		}
+		if op == "move" {
+			if from == path {
+				// from 也必须存在
+				if _, err := getPointer(root, from); err != nil {
+					return nil, err
+				}
+				return root, nil
+			}
+			// 不能移动到自己的子节点下
+			if strings.HasPrefix(path, from+"/") {
+				return nil, fmt.Errorf("can't move '%s' to its child '%s'", from, path)
+			}
+			value, err := removePointer(root, from)
+			if err != nil {
+				return nil, err
+			}
+			return setPointer(root, path, value, pointerAdd)
+		}
+		r, err := getPointer(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return setPointer(root, path, deepCopy(r.object), pointerAdd)
+	default:
+		return nil, fmt.Errorf("unknown op '%s'", op)
+	}
+}
+
+func operationMember(m map[string]interface{}, name string) (string, error) {
+	v, ok := m[name]
+	if !ok {
+		return "", fmt.Errorf("missing '%s'", name)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("'%s' must be a string", name)
+	}
+	return s, nil
+}
+
+// deepCopy copy the objects and arrays of the decoded tree.
+func deepCopy(v interface{}) interface{} {
+	switch data := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(data))
+		for k, e := range data {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case *[]interface{}:
+		arr := make([]interface{}, len(*data))
+		for i, e := range *data {
+			arr[i] = deepCopy(e)
+		}
+		return &arr
+	default:
+		return v
+	}
+}
+
+// Diff return the RFC 6902 JSON Patch which change a to b.
+func Diff(a, b string) (string, error) {
+	x, err := DecodeWithNumberMode(a, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	y, err := DecodeWithNumberMode(b, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	operations := diffValue("", x, y, make([]patchOperation, 0))
+	data, err := Marshal(operations)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func diffValue(path string, x, y interface{}, operations []patchOperation) []patchOperation {
+	switch xv := x.(type) {
+	case map[string]interface{}:
+		yv, ok := y.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(xv)+len(yv))
+		for k := range xv {
+			keys = append(keys, k)
+		}
+		for k := range yv {
+			if _, ok := xv[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "/" + escapePointer(k)
+			v1, ok1 := xv[k]
+			v2, ok2 := yv[k]
+			switch {
+			case !ok2:
+				operations = append(operations, patchOperation{Op: "remove", Path: child})
+			case !ok1:
+				operations = append(operations, patchOperation{Op: "add", Path: child, Value: v2})
+			default:
+				operations = diffValue(child, v1, v2, operations)
+			}
+		}
+		return operations
+	case *[]interface{}:
+		yv, ok := y.(*[]interface{})
+		if !ok {
+			break
+		}
+		n := len(*xv)
+		if len(*yv) < n {
+			n = len(*yv)
+		}
+		for i := 0; i < n; i++ {
+			operations = diffValue(path+"/"+strconv.Itoa(i), (*xv)[i], (*yv)[i], operations)
+		}
+		// 从后往前删除，前面的下标不会变
+		for i := len(*xv) - 1; i >= n; i-- {
+			operations = append(operations, patchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		for i := n; i < len(*yv); i++ {
+			operations = append(operations, patchOperation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: (*yv)[i]})
+		}
+		return operations
+	}
+	if !equalValues(x, y) {
+		operations = append(operations, patchOperation{Op: "replace", Path: path, Value: y})
+	}
+	return operations
+}
+
+// MergePatch apply the RFC 7386 JSON Merge Patch to target and return the patched json,
+// the member whose value is null in patch is removed from target.
+func MergePatch(target, patch string) (string, error) {
+	t, err := DecodeWithNumberMode(target, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	p, err := DecodeWithNumberMode(patch, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	data, err := Marshal(mergePatch(t, p))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if _, ok := v.(NullValue); ok {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
@@ -0,0 +1,165 @@
+package xjson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// the examples of RFC 6902
+	s, err := ApplyPatch(`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"baz":"qux","foo":"bar"}`)
+	s, err = ApplyPatch(`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":["bar","qux","baz"]}`)
+	s, err = ApplyPatch(`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":"bar"}`)
+	s, err = ApplyPatch(`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":["bar","baz"]}`)
+	s, err = ApplyPatch(`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"baz":"boo","foo":"bar"}`)
+	s, err = ApplyPatch(`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`)
+	s, err = ApplyPatch(`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":["all","cows","eat","grass"]}`)
+	s, err = ApplyPatch(`{"baz":"qux","foo":["a",2,"c"]}`,
+		`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"baz":"qux","foo":["a",2,"c"]}`)
+	s, err = ApplyPatch(`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"child":{"grandchild":{}},"foo":"bar"}`)
+	s, err = ApplyPatch(`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"foo":["bar",["abc","def"]]}`)
+	s, err = ApplyPatch(`{"foo":null}`, `[{"op":"test","path":"/foo","value":null},{"op":"copy","from":"/foo","path":"/bar"}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"bar":null,"foo":null}`)
+	s, err = ApplyPatch(`{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"a":{"b":[1]},"c":{"b":[1,2]}}`)
+	s, err = ApplyPatch(`{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `[1]`)
+}
+
+func TestApplyPatchError(t *testing.T) {
+	for _, patch := range []string{
+		`{}`,
+		`[1]`,
+		`[{"path":"/a"}]`,
+		`[{"op":"add"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"foo","path":"/a"}]`,
+		`[{"op":"add","path":"/a/b/c","value":1}]`,
+		`[{"op":"add","path":"/list/5","value":1}]`,
+		`[{"op":"remove","path":"/b"}]`,
+		`[{"op":"remove","path":""}]`,
+		`[{"op":"replace","path":"/b","value":1}]`,
+		`[{"op":"move","from":"/a","path":"/a/b"}]`,
+		`[{"op":"move","from":"/b","path":"/c"}]`,
+		`[{"op":"move","from":"/b","path":"/b"}]`,
+		`[{"op":"copy","path":"/c"}]`,
+		`[{"op":"test","path":"/a","value":"1"}]`,
+		`[{"op":"test","path":"/b","value":1}]`,
+		`[{"op":"add","path":"/x","value":1},{"op":"test","path":"/a","value":2}]`,
+	} {
+		_, err := ApplyPatch(`{"a":1,"list":[1]}`, patch)
+		fmt.Println(err)
+		assert.NotNil(t, err, patch)
+	}
+	_, err := ApplyPatch(`{`, `[]`)
+	assert.NotNil(t, err)
+}
+
+func TestApplyPatchBigNumber(t *testing.T) {
+	doc := `{"id":12345678901234567890}`
+	_, err := ApplyPatch(doc, `[{"op":"test","path":"/id","value":12345678901234567891}]`)
+	assert.NotNil(t, err)
+	_, err = ApplyPatch(doc, `[{"op":"test","path":"/id","value":12345678901234567890}]`)
+	assert.Nil(t, err)
+	_, err = ApplyPatch(doc, `[{"op":"test","path":"/id","value":1.2345678901234567890e19}]`)
+	assert.Nil(t, err)
+	_, err = ApplyPatch(`{"a":1e400}`, `[{"op":"test","path":"/a","value":1e401}]`)
+	assert.NotNil(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := `{"name":"bob","age":20,"tags":["a","b","c"],"address":{"city":"sz","zip":"1"},"a/b":1}`
+	b := `{"name":"alice","age":20.0,"tags":["a","x"],"address":{"city":"sz","street":"s"},"n":null}`
+	patch, err := Diff(a, b)
+	assert.Nil(t, err)
+	fmt.Println(patch)
+	assert.Equal(t, patch, `[{"op":"remove","path":"/a~1b"},{"op":"add","path":"/address/street","value":"s"},`+
+		`{"op":"remove","path":"/address/zip"},{"op":"add","path":"/n","value":null},{"op":"replace","path":"/name","value":"alice"},`+
+		`{"op":"replace","path":"/tags/1","value":"x"},{"op":"remove","path":"/tags/2"}]`)
+	s, err := ApplyPatch(a, patch)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"address":{"city":"sz","street":"s"},"age":20,"n":null,"name":"alice","tags":["a","x"]}`)
+
+	patch, err = Diff(`[1]`, `[1,[2],{"a":3}]`)
+	assert.Nil(t, err)
+	assert.Equal(t, patch, `[{"op":"add","path":"/1","value":[2]},{"op":"add","path":"/2","value":{"a":3}}]`)
+	patch, err = Diff(`{"a":1}`, `[1]`)
+	assert.Nil(t, err)
+	assert.Equal(t, patch, `[{"op":"replace","path":"","value":[1]}]`)
+	patch, err = Diff(`{"a":1}`, `{"a":1}`)
+	assert.Nil(t, err)
+	assert.Equal(t, patch, `[]`)
+	// 大数不能按 float64 比较
+	patch, err = Diff(`{"id":12345678901234567890}`, `{"id":12345678901234567891}`)
+	assert.Nil(t, err)
+	assert.Equal(t, patch, `[{"op":"replace","path":"/id","value":12345678901234567891}]`)
+	patch, err = Diff(`{"id":12345678901234567890}`, `{"id":12345678901234567890.0}`)
+	assert.Nil(t, err)
+	assert.Equal(t, patch, `[]`)
+	_, err = Diff(`{`, `{}`)
+	assert.NotNil(t, err)
+}
+
+func TestMergePatch(t *testing.T) {
+	s, err := MergePatch(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`,
+		`{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`)
+	assert.Nil(t, err)
+	fmt.Println(s)
+	assert.Equal(t, s, `{"author":{"givenName":"John"},"content":"This will be unchanged","phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`)
+
+	// the examples of RFC 7386
+	for _, c := range [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"a":""}`, `{"a":null}`, `{}`},
+		{`{"a":1.50}`, `{"b":{}}`, `{"a":1.50,"b":{}}`},
+	} {
+		s, err := MergePatch(c[0], c[1])
+		assert.Nil(t, err)
+		assert.Equal(t, s, c[2], c[1])
+	}
+
+	_, err = MergePatch(`{`, `{}`)
+	assert.NotNil(t, err)
+	_, err = MergePatch(`{}`, `{`)
+	assert.NotNil(t, err)
+}
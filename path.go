@@ -0,0 +1,308 @@
+package xjson
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// getWithRoot query grammar from root, the empty grammar return root itself.
+func getWithRoot(root interface{}, grammar string) Result {
+	result, err := getWithRootE(root, grammar)
+	if err != nil {
+		return buildEmptyResult()
+	}
+	return result
+}
+
+func getWithRootE(root interface{}, grammar string) (Result, error) {
+	tokenize, err := GrammarTokenize(grammar)
+	if err != nil {
+		return buildEmptyResult(), newPathSyntaxError(grammar, "", err.Error())
+	}
+	result := Result{
+		Token:  typeOfToken(root),
+		object: root,
+	}
+	if len(tokenize) == 0 {
+		return result, nil
+	}
+	return walkPath(grammar, result, tokenize, KeyStatus|BeginArrayIndexStatus)
+}
+
+// walkPath walk the tokens from result, status is the allowed status of the first token.
+func walkPath(grammar string, result Result, tokens []*GrammarTokenType, status GrammarStatus) (Result, error) {
+	reader := NewGrammarTokenReader(tokens)
+	for {
+		read := reader.Read()
+		switch read.T {
+		case Key:
+			// ["first-name"] 括号中的 key
+			bracket := includeGrammarTokenStatus(ArrayIndexStatus, status)
+			if !bracket && !includeGrammarTokenStatus(KeyStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected key")
+			}
+			m, ok := result.object.(map[string]interface{})
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, read.Value, fmt.Sprintf("parent is %s, not JSONObject", result.Token))
+			}
+			v, ok := m[read.Value]
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, read.Value, "key not found")
+			}
+			result = Result{
+				Token:  typeOfToken(v),
+				object: v,
+			}
+			status = DotStatus | BeginArrayIndexStatus
+			if bracket {
+				status = EndArrayIndexStatus
+			}
+		case Wildcard:
+			if !includeGrammarTokenStatus(KeyStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected '*'")
+			}
+			values, ok := childValues(result.object)
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, read.Value, fmt.Sprintf("parent is %s, not JSONObject or ArrayObject", result.Token))
+			}
+			// obj.*.name 剩下的路径作用于每一个值
+			return projectPath(grammar, values, tokens[reader.pos:], DotStatus|BeginArrayIndexStatus)
+		case BeginArrayIndex:
+			if !includeGrammarTokenStatus(BeginArrayIndexStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected '['")
+			}
+			status = ArrayIndexStatus
+		case ArrayIndex:
+			segment := "[" + read.Value + "]"
+			if !includeGrammarTokenStatus(ArrayIndexStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, segment, "unexpected index")
+			}
+			a, ok := result.object.(*[]interface{})
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, segment, fmt.Sprintf("parent is %s, not ArrayObject", result.Token))
+			}
+			if read.Value == "*" {
+				// people[*].name
+				return projectPath(grammar, *a, tokens[reader.pos:], EndArrayIndexStatus)
+			}
+			if strings.Contains(read.Value, ":") {
+				// items[1:4] items[::2]
+				values, err := sliceValues(*a, read.Value)
+				if err != nil {
+					return buildEmptyResult(), newPathSyntaxError(grammar, segment, err.Error())
+				}
+				return projectPath(grammar, values, tokens[reader.pos:], EndArrayIndexStatus)
+			}
+			index, err := strconv.Atoi(read.Value)
+			if err != nil {
+				return buildEmptyResult(), newPathSyntaxError(grammar, segment, "invalid index")
+			}
+			if index < 0 {
+				// 负数从末尾开始
+				index += len(*a)
+			}
+			if index < 0 || index >= len(*a) {
+				return buildEmptyResult(), newPathError(grammar, segment, fmt.Sprintf("index out of range with length %d", len(*a)))
+			}
+			v := (*a)[index]
+			result = Result{
+				Token:  typeOfToken(v),
+				object: v,
+			}
+			status = EndArrayIndexStatus
+		case Filter:
+			segment := "[?" + read.Value + "]"
+			if !includeGrammarTokenStatus(ArrayIndexStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, segment, "unexpected filter")
+			}
+			expr, err := parseFilter(read.Value)
+			if err != nil {
+				return buildEmptyResult(), newPathSyntaxError(grammar, segment, err.Error())
+			}
+			values, ok := childValues(result.object)
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, segment, fmt.Sprintf("parent is %s, not JSONObject or ArrayObject", result.Token))
+			}
+			matches := make([]interface{}, 0, len(values))
+			for _, v := range values {
+				if expr.eval(v).truthy() {
+					matches = append(matches, v)
+				}
+			}
+			// people[?(@.age > 18)].name 剩下的路径作用于每一个匹配的值
+			return projectPath(grammar, matches, tokens[reader.pos:], EndArrayIndexStatus)
+		case Length:
+			if !includeGrammarTokenStatus(KeyStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected '#'")
+			}
+			a, ok := result.object.(*[]interface{})
+			if !ok {
+				return buildEmptyResult(), newPathError(grammar, read.Value, fmt.Sprintf("parent is %s, not ArrayObject", result.Token))
+			}
+			if reader.HasNext() {
+				// people.#.name 和 people[*].name 一样
+				return projectPath(grammar, *a, tokens[reader.pos:], DotStatus)
+			}
+			result = Result{
+				Token:  Number,
+				object: len(*a),
+			}
+			status = EndStatus
+		case EndArrayIndex:
+			if !includeGrammarTokenStatus(EndArrayIndexStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected ']'")
+			}
+			status = DotStatus | BeginArrayIndexStatus
+		case Descent:
+			if !includeGrammarTokenStatus(DotStatus|BeginArrayIndexStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected '..'")
+			}
+			// store..price 剩下的路径作用于 store 自己和它下面的每一个值
+			values := descendants(result.object, nil)
+			return projectPath(grammar, values, tokens[reader.pos:], KeyStatus|BeginArrayIndexStatus)
+		case Dot:
+			if !includeGrammarTokenStatus(DotStatus, status) {
+				return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected '.'")
+			}
+			status = KeyStatus
+
+		case EOF:
+			if !includeGrammarTokenStatus(DotStatus|EndStatus, status) {
+				// syntax error
+				return buildEmptyResult(), newPathSyntaxError(grammar, "", "unexpected end of path")
+			}
+			return result, nil
+		default:
+			return buildEmptyResult(), newPathSyntaxError(grammar, read.Value, "unexpected token")
+		}
+	}
+}
+
+// projectPath walk the rest tokens from every value and collect the matches to an ArrayObject,
+// the value which doesn't match is skipped.
+func projectPath(grammar string, values []interface{}, tokens []*GrammarTokenType, status GrammarStatus) (Result, error) {
+	matches := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		r, err := walkPath(grammar, Result{Token: typeOfToken(v), object: v}, tokens, status)
+		if err != nil {
+			if e, ok := err.(*PathError); ok && e.syntax {
+				return buildEmptyResult(), err
+			}
+			continue
+		}
+		matches = append(matches, r.object)
+	}
+	return Result{
+		Token:  ArrayObject,
+		object: &matches,
+	}, nil
+}
+
+// childValues return the values of an object sorted by key, or the elements of an array.
+func childValues(object interface{}) ([]interface{}, bool) {
+	switch data := object.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(data))
+		for _, k := range keys {
+			values = append(values, data[k])
+		}
+		return values, true
+	case *[]interface{}:
+		return *data, true
+	default:
+		return nil, false
+	}
+}
+
+// sliceValues return the elements of a selected by start:end:step, like the slice of Python.
+func sliceValues(a []interface{}, slice string) ([]interface{}, error) {
+	parts := strings.Split(slice, ":")
+	if len(parts) > 3 {
+		return nil, errors.New("invalid slice")
+	}
+	bounds := make([]*int, 3)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, errors.New("invalid slice")
+		}
+		bounds[i] = &n
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil, errors.New("slice step cannot be zero")
+	}
+	return sliceRange(a, bounds[0], bounds[1], step), nil
+}
+
+// sliceRange return the elements of a from start to end by step, the nil bound is the default,
+// step must not be zero.
+func sliceRange(a []interface{}, startBound, endBound *int, step int) []interface{} {
+	length := len(a)
+	// 步长为负数时从后往前取
+	var start, end int
+	if step > 0 {
+		start, end = 0, length
+	} else {
+		start, end = length-1, -1
+	}
+	if startBound != nil {
+		start = sliceBound(*startBound, length, step)
+	}
+	if endBound != nil {
+		end = sliceBound(*endBound, length, step)
+	}
+
+	values := make([]interface{}, 0)
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		values = append(values, a[i])
+	}
+	return values
+}
+
+// sliceBound normalize a negative or out of range bound.
+func sliceBound(n, length, step int) int {
+	if n < 0 {
+		n += length
+	}
+	if step > 0 {
+		if n < 0 {
+			return 0
+		}
+		if n > length {
+			return length
+		}
+		return n
+	}
+	if n < 0 {
+		return -1
+	}
+	if n >= length {
+		return length - 1
+	}
+	return n
+}
+
+// descendants append object and all the values nested in it to values, depth first.
+func descendants(object interface{}, values []interface{}) []interface{} {
+	values = append(values, object)
+	children, _ := childValues(object)
+	for _, child := range children {
+		values = descendants(child, values)
+	}
+	return values
+}
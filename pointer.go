@@ -0,0 +1,227 @@
+package xjson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetPointer query json by a RFC 6901 JSON Pointer like /people/0/name,
+// '~' and '/' in a key are escaped as ~0 and ~1.
+func GetPointer(json, ptr string) Result {
+	r, err := GetPointerE(json, ptr)
+	if err != nil {
+		return buildEmptyResult()
+	}
+	return r
+}
+
+// GetPointerE is like GetPointer, but return the error which explain why the query failed.
+func GetPointerE(json, ptr string) (Result, error) {
+	decode, err := Decode(json)
+	if err != nil {
+		return buildEmptyResult(), err
+	}
+	return getPointer(decode, ptr)
+}
+
+// GetPointer query ptr relative to the result.
+func (r Result) GetPointer(ptr string) Result {
+	if !r.Exists() {
+		return buildEmptyResult()
+	}
+	result, err := getPointer(r.object, ptr)
+	if err != nil {
+		return buildEmptyResult()
+	}
+	return result
+}
+
+// parsePointer split ptr to unescaped reference tokens, the empty pointer refer to the whole document.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, newPathSyntaxError(ptr, "", "pointer must start with '/'")
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 >= len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, newPathSyntaxError(ptr, token, "invalid escape, only ~0 and ~1 are allowed")
+			}
+		}
+		// ~1 要先于 ~0 替换，~01 是 ~1 而不是 /
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// escapePointer escape '~' and '/' of a key.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func getPointer(root interface{}, ptr string) (Result, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return buildEmptyResult(), err
+	}
+	current := root
+	for _, token := range tokens {
+		switch data := current.(type) {
+		case map[string]interface{}:
+			v, ok := data[token]
+			if !ok {
+				return buildEmptyResult(), newPathError(ptr, token, "key not found")
+			}
+			current = v
+		case *[]interface{}:
+			index, err := pointerIndex(token, len(*data))
+			if err != nil {
+				return buildEmptyResult(), newPathError(ptr, token, err.Error())
+			}
+			if index == len(*data) {
+				return buildEmptyResult(), newPathError(ptr, token, "refer to the element after the last")
+			}
+			current = (*data)[index]
+		default:
+			return buildEmptyResult(), newPathError(ptr, token, fmt.Sprintf("parent is %s, not JSONObject or ArrayObject", typeOfToken(current)))
+		}
+	}
+	return Result{
+		Token:  typeOfToken(current),
+		object: current,
+	}, nil
+}
+
+// pointerIndex parse the array index, "-" is length, which is the element after the last one.
+func pointerIndex(token string, length int) (int, error) {
+	if token == "-" {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.New("invalid index")
+	}
+	for i := 0; i < len(token); i++ {
+		if !isDigit(token[i]) {
+			return 0, errors.New("invalid index")
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index > length {
+		return 0, fmt.Errorf("index out of range with length %d", length)
+	}
+	return index, nil
+}
+
+// pointerOp is how setPointer change the target.
+type pointerOp int
+
+const (
+	// pointerSet add the member or replace the element of array.
+	pointerSet pointerOp = iota
+	// pointerAdd add the member or insert the element before index, like the add of JSON Patch.
+	pointerAdd
+	// pointerReplace the target must exist.
+	pointerReplace
+)
+
+// setPointer set value to ptr, return the new root because the root itself may be replaced.
+func setPointer(root interface{}, ptr string, value interface{}, op pointerOp) (interface{}, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := getPointer(root, pointerOf(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch data := parent.object.(type) {
+	case map[string]interface{}:
+		if _, ok := data[last]; !ok && op == pointerReplace {
+			return nil, newPathError(ptr, last, "key not found")
+		}
+		data[last] = value
+	case *[]interface{}:
+		index, err := pointerIndex(last, len(*data))
+		if err != nil {
+			return nil, newPathError(ptr, last, err.Error())
+		}
+		if index == len(*data) {
+			if op == pointerReplace {
+				return nil, newPathError(ptr, last, "refer to the element after the last")
+			}
+			*data = append(*data, value)
+		} else if op == pointerAdd {
+			*data = append(*data, nil)
+			copy((*data)[index+1:], (*data)[index:])
+			(*data)[index] = value
+		} else {
+			(*data)[index] = value
+		}
+	default:
+		return nil, newPathError(ptr, last, fmt.Sprintf("parent is %s, not JSONObject or ArrayObject", parent.Token))
+	}
+	return root, nil
+}
+
+// removePointer remove the target of ptr and return it, the root can't be removed.
+func removePointer(root interface{}, ptr string) (interface{}, error) {
+	tokens, err := parsePointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, newPathError(ptr, "", "can't remove the root")
+	}
+	parent, err := getPointer(root, pointerOf(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch data := parent.object.(type) {
+	case map[string]interface{}:
+		v, ok := data[last]
+		if !ok {
+			return nil, newPathError(ptr, last, "key not found")
+		}
+		delete(data, last)
+		return v, nil
+	case *[]interface{}:
+		index, err := pointerIndex(last, len(*data))
+		if err != nil || index == len(*data) {
+			return nil, newPathError(ptr, last, fmt.Sprintf("index out of range with length %d", len(*data)))
+		}
+		v := (*data)[index]
+		*data = append((*data)[:index], (*data)[index+1:]...)
+		return v, nil
+	default:
+		return nil, newPathError(ptr, last, fmt.Sprintf("parent is %s, not JSONObject or ArrayObject", parent.Token))
+	}
+}
+
+// pointerOf join the tokens to a pointer.
+func pointerOf(tokens []string) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteByte('/')
+		builder.WriteString(escapePointer(token))
+	}
+	return builder.String()
+}
+
+// toTree covert a Go value to the decoded tree, so that it can be set into the tree.
+func toTree(v interface{}) (interface{}, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(string(data))
+}
@@ -0,0 +1,86 @@
+package xjson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the example of RFC 6901
+const pointerJSON = `{
+  "foo": ["bar", "baz"],
+  "": 0,
+  "a/b": 1,
+  "c%d": 2,
+  "e^f": 3,
+  "g|h": 4,
+  "i\\j": 5,
+  "k\"l": 6,
+  " ": 7,
+  "m~n": 8,
+  "a.b[0]": 9
+}`
+
+func TestGetPointer(t *testing.T) {
+	assert.Equal(t, GetPointer(pointerJSON, "").Token, Token(JSONObject))
+	assert.Equal(t, GetPointer(pointerJSON, "/foo").String(), `["bar","baz"]`)
+	assert.Equal(t, GetPointer(pointerJSON, "/foo/0").String(), "bar")
+	assert.Equal(t, GetPointer(pointerJSON, "/").Int(), 0)
+	assert.Equal(t, GetPointer(pointerJSON, "/").Exists(), true)
+	assert.Equal(t, GetPointer(pointerJSON, "/a~1b").Int(), 1)
+	assert.Equal(t, GetPointer(pointerJSON, "/c%d").Int(), 2)
+	assert.Equal(t, GetPointer(pointerJSON, "/e^f").Int(), 3)
+	assert.Equal(t, GetPointer(pointerJSON, "/g|h").Int(), 4)
+	assert.Equal(t, GetPointer(pointerJSON, "/i\\j").Int(), 5)
+	assert.Equal(t, GetPointer(pointerJSON, "/k\"l").Int(), 6)
+	assert.Equal(t, GetPointer(pointerJSON, "/ ").Int(), 7)
+	assert.Equal(t, GetPointer(pointerJSON, "/m~0n").Int(), 8)
+	assert.Equal(t, GetPointer(pointerJSON, "/a.b[0]").Int(), 9)
+	assert.Equal(t, GetPointer(`{"~1":1,"/":2}`, "/~01").Int(), 1)
+	assert.Equal(t, GetPointer(`[[1,2],[3]]`, "/1/0").Int(), 3)
+	assert.Equal(t, GetPointer(pointerJSON, "/foo").GetPointer("/1").String(), "baz")
+
+	for _, ptr := range []string{"foo", "/bar", "/foo/2", "/foo/-", "/foo/01", "/foo/-1", "/foo/a", "/foo/0/x", "/m~2n", "/m~"} {
+		r, err := GetPointerE(pointerJSON, ptr)
+		fmt.Println(err)
+		assert.NotNil(t, err, ptr)
+		assert.Equal(t, r.Exists(), false)
+	}
+}
+
+func TestDocumentPointer(t *testing.T) {
+	doc, err := ParseDocument(`{"people":[{"name":"bob"}],"a/b":{}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, doc.GetPointer("/people/0/name").String(), "bob")
+
+	assert.Nil(t, doc.SetPointer("/people/0/name", "alice"))
+	assert.Nil(t, doc.SetPointer("/people/-", map[string]interface{}{"name": "tom", "tags": []string{"a"}}))
+	assert.Nil(t, doc.SetPointer("/a~1b/c", 1.5))
+	assert.Equal(t, doc.Get("people[0].name").String(), "alice")
+	assert.Equal(t, doc.Get("people[1].tags[0]").String(), "a")
+	assert.Equal(t, doc.GetPointer("/a~1b/c").Float(), 1.5)
+	assert.NotNil(t, doc.SetPointer("/people/5", 1))
+	assert.NotNil(t, doc.SetPointer("/x/y", 1))
+
+	assert.Nil(t, doc.RemovePointer("/people/0"))
+	assert.Nil(t, doc.RemovePointer("/a~1b"))
+	assert.Equal(t, doc.String(), `{"people":[{"name":"tom","tags":["a"]}]}`)
+	assert.NotNil(t, doc.RemovePointer("/people/1"))
+	assert.NotNil(t, doc.RemovePointer(""))
+
+	assert.Nil(t, doc.SetPointer("", []int{1, 2}))
+	assert.Equal(t, doc.String(), `[1,2]`)
+}
+
+func TestSetPointerAdd(t *testing.T) {
+	decode, _ := Decode(`{"a":[1,2,3]}`)
+	root, err := setPointer(decode, "/a/1", 9, pointerAdd)
+	assert.Nil(t, err)
+	assert.Equal(t, getWithRoot(root, "a").String(), `[1,9,2,3]`)
+	_, err = setPointer(decode, "/b", 9, pointerReplace)
+	assert.NotNil(t, err)
+	_, err = setPointer(decode, "/a/-", 9, pointerReplace)
+	assert.NotNil(t, err)
+	assert.Equal(t, pointerOf([]string{"a/b", "m~n"}), "/a~1b/m~0n")
+}
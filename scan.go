@@ -0,0 +1,229 @@
+package xjson
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// getLazy query the simple path which only contain keys and indexes by scanning json like gjson,
+// the values are skipped without being decoded or checked and only the target is decoded. ok is false
+// when the path can't be scanned, the tree should be used then.
+func getLazy(json, grammar string) (result Result, ok bool) {
+	if grammar == "" {
+		return buildEmptyResult(), false
+	}
+	steps, err := pathSteps(grammar)
+	if err != nil || len(steps) == 0 {
+		return buildEmptyResult(), false
+	}
+	for _, step := range steps {
+		// 负数下标要先知道数组的长度
+		if step.isIndex && step.index < 0 {
+			return buildEmptyResult(), false
+		}
+	}
+	start, end, found := scanPath(json, 0, steps)
+	if !found {
+		return buildEmptyResult(), true
+	}
+	v, err := parse(NewLexerString(json[start:end]), NumberModeDefault)
+	if err != nil {
+		return buildEmptyResult(), true
+	}
+	return Result{
+		Token:  typeOfToken(v),
+		object: v,
+	}, true
+}
+
+// scanPath find the steps in the value at json[i] and return the position of the target,
+// the last member is used when the key is duplicated, the same as the decoded tree.
+func scanPath(json string, i int, steps []pathStep) (start, end int, found bool) {
+	i = skipSpace(json, i)
+	for _, step := range steps {
+		if i >= len(json) {
+			return 0, 0, false
+		}
+		switch {
+		case json[i] == '{' && !step.isIndex:
+			m, n, _ := scanObject(json, i, step.key)
+			if n == 0 {
+				return 0, 0, false
+			}
+			i = m.value
+		case json[i] == '[' && step.isIndex:
+			m, ok, _, _ := scanArray(json, i, step.index)
+			if !ok {
+				return 0, 0, false
+			}
+			i = m.value
+		default:
+			return 0, 0, false
+		}
+	}
+	end, found = skipValue(json, i)
+	return i, end, found
+}
+
+// member is the position of an object member or an array element in json.
+type member struct {
+	// start is the start of the key or the element, value is the start of the value
+	start int
+	value int
+	end   int
+	// prev is the end of the previous value and next is the start of the next one, -1 when there is none
+	prev int
+	next int
+}
+
+// scanObject find key in the object at json[i], the last member is used when the key is duplicated.
+// n is the count of the members with key, end is the position of '}', it is -1 when the object is invalid.
+func scanObject(json string, i int, key string) (m member, n int, end int) {
+	end, prev := -1, -1
+	i = skipSpace(json, i+1)
+	if i < len(json) && json[i] == '}' {
+		return m, 0, i
+	}
+	for {
+		if n > 0 && m.next < 0 {
+			m.next = i
+		}
+		if i >= len(json) || json[i] != '"' {
+			return
+		}
+		start := i
+		var ok bool
+		if i, ok = skipString(json, i); !ok {
+			return
+		}
+		match := keyEqual(json[start:i], key)
+		i = skipSpace(json, i)
+		if i >= len(json) || json[i] != ':' {
+			return
+		}
+		value := skipSpace(json, i+1)
+		if i, ok = skipValue(json, value); !ok {
+			return
+		}
+		if match {
+			m = member{start: start, value: value, end: i, prev: prev, next: -1}
+			n++
+		}
+		prev = i
+		i = skipSpace(json, i)
+		if i >= len(json) {
+			return
+		}
+		if json[i] == '}' {
+			return m, n, i
+		}
+		if json[i] != ',' {
+			return
+		}
+		i = skipSpace(json, i+1)
+	}
+}
+
+// scanArray find the element at index in the array at json[i], the scan stops after the element.
+// count is the count of the elements scanned, end is the position of ']', it is -1 when the scan stops
+// before ']' or the array is invalid.
+func scanArray(json string, i, index int) (m member, found bool, count, end int) {
+	end, prev := -1, -1
+	i = skipSpace(json, i+1)
+	if i < len(json) && json[i] == ']' {
+		return m, false, 0, i
+	}
+	for {
+		start := i
+		var ok bool
+		if i, ok = skipValue(json, start); !ok {
+			return
+		}
+		if count == index {
+			m = member{start: start, value: start, end: i, prev: prev, next: -1}
+			found = true
+		}
+		count++
+		prev = i
+		i = skipSpace(json, i)
+		if i >= len(json) {
+			return
+		}
+		if json[i] == ']' {
+			return m, found, count, i
+		}
+		if json[i] != ',' {
+			return
+		}
+		i = skipSpace(json, i+1)
+		if found {
+			m.next = i
+			return
+		}
+	}
+}
+
+// keyEqual compare the quoted key with key, the escape sequences are unescaped one by one.
+func keyEqual(quoted, key string) bool {
+	raw := quoted[1 : len(quoted)-1]
+	if strings.IndexByte(raw, '\\') < 0 {
+		return raw == key
+	}
+	var (
+		seq [11]byte // 最长的转义序列 uXXXX\uXXXX
+		buf [utf8.UTFMax]byte
+	)
+	k := 0
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			if k >= len(key) || key[k] != raw[i] {
+				return false
+			}
+			k++
+			continue
+		}
+		n := copy(seq[:], raw[i+1:])
+		if n == 0 {
+			return false
+		}
+		v, m, err := unescape(seq[:n], 0, buf[:0])
+		if err != nil || k+len(v) > len(key) || key[k:k+len(v)] != string(v) {
+			return false
+		}
+		k += len(v)
+		i += m + 1
+	}
+	return k == len(key)
+}
+
+// skipValue find the end of the value at json[i], the value is not checked.
+func skipValue(json string, i int) (int, bool) {
+	if i >= len(json) {
+		return 0, false
+	}
+	first := json[i]
+	scalar := first != '{' && first != '[' && first != '"'
+	if scalar && (isWhitespace(first) || isDelimiter(first)) {
+		return 0, false
+	}
+	var s valueScanner
+	n, ok := s.scan(json[i:], 0)
+	if !ok {
+		// number, true, false, null 可以在末尾结束
+		return len(json), scalar
+	}
+	return i + n, true
+}
+
+// skipString find the end of the string at json[i].
+func skipString(json string, i int) (int, bool) {
+	end, _ := stringEnd(json, i+1)
+	return end + 1, end < len(json)
+}
+
+func skipSpace(json string, i int) int {
+	for i < len(json) && isWhitespace(json[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,72 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetLazy(t *testing.T) {
+	str := `{"name":"bob", "age":20, "a\"b":1, "skill":{"lang":[{"go":{"feature":["goroutine","channel"]}},{"java":null}]},
+"empty":{}, "list":[], "long":99.99, "big":12345678901234567890, "ok":true}`
+	paths := []string{"name", "age", `["a\"b"]`, "skill.lang", "skill.lang[0].go.feature[1]", "skill.lang[1].java",
+		"skill.lang[2]", "empty", "list", "list[0]", "long", "big", "ok", "name.first", "skill[0]", "none"}
+	for _, path := range paths {
+		lazy, ok := getLazy(str, path)
+		assert.True(t, ok, path)
+		decode, err := Decode(str)
+		assert.Nil(t, err)
+		expected := getWithRoot(decode, path)
+		fmt.Println(path, lazy.Token, lazy.object)
+		assert.Equal(t, lazy, expected, path)
+	}
+}
+
+func TestGetLazyFallback(t *testing.T) {
+	str := `{"list":[1,2,3]}`
+	for _, path := range []string{"", "list[-1]", "list.#", "list[*]", "list[0:2]", "..list"} {
+		_, ok := getLazy(str, path)
+		assert.False(t, ok, path)
+	}
+	assert.Equal(t, Get(str, "list[-1]").Int(), 3)
+	assert.Equal(t, Get(str, "list.#").Int(), 3)
+}
+
+func TestGetLazyInvalid(t *testing.T) {
+	// 目标本身不合法时返回空
+	for _, str := range []string{`{"a":tru}`, `{"a":"\x"}`, `{"a":[1,}`, `{"a":01}`, `{"b":[1,"a"}`, `[`} {
+		assert.Equal(t, Get(str, "a"), buildEmptyResult(), str)
+	}
+	// 目标后面的 json 不检查，GetE 会检查整个 json
+	for _, str := range []string{`{"a":1,"b":}`, `{"a":1`} {
+		assert.Equal(t, Get(str, "a").Int(), 1)
+		_, err := GetE(str, "a")
+		assert.NotNil(t, err)
+	}
+}
+
+func TestGetLazyDuplicateKey(t *testing.T) {
+	// 和 Decode 一样使用最后一个 key
+	str := `{"a":[1],"b":0,"a":[2,3]}`
+	assert.Equal(t, Get(str, "a[0]").Int(), 2)
+	assert.Equal(t, Get(str, "a[-2]").Int(), 2)
+	get, err := GetE(str, "a[0]")
+	assert.Nil(t, err)
+	assert.Equal(t, get.Int(), 2)
+	doc, err := ParseDocument(str)
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Get("a[0]").Int(), 2)
+	assert.Equal(t, Get(`{"a":{"b":1},"a":{"c":1}}`, "a.b"), buildEmptyResult())
+	assert.Equal(t, Get(`{"a":{"b":1},"a":{"c":1}}`, "a.c").Int(), 1)
+}
+
+func TestGetLazyAllocs(t *testing.T) {
+	str := `{"list":[{"name":"a\tb","age":10.5},{"name":"c","ok":[true,false,null]}],"x\ty":1,"name":"bob"}`
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _, _ = scanPath(str, 0, []pathStep{{key: "name"}})
+	})
+	assert.Equal(t, allocs, float64(0))
+	start, end, ok := scanPath(str, 0, []pathStep{{key: "list"}, {index: 1, isIndex: true}, {key: "ok"}, {index: 2, isIndex: true}})
+	assert.True(t, ok)
+	assert.Equal(t, str[start:end], "null")
+}
@@ -0,0 +1,457 @@
+package xjson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Set set value to the path of json and return the modified json, the missing objects are created
+// and the arrays grow with null, value can be any Go value which can be marshalled.
+// Only keys and indexes can be used in the path. The value is spliced into json, so the rest of json
+// is kept as it is and is not checked, like Get.
+func Set(json, path string, value interface{}) (string, error) {
+	raw, err := Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return SetRaw(json, path, string(raw))
+}
+
+// SetRaw is like Set, but value is raw JSON, it is checked and spliced into json as it is.
+func SetRaw(json, path, raw string) (string, error) {
+	// 使用 RawNumber 保持数字原样输出
+	v, err := DecodeWithNumberMode(raw, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	return setJSON(json, path, strings.TrimSpace(raw), v)
+}
+
+// Set set value to the path, like xjson.Set.
+func (d *Document) Set(path string, value interface{}) error {
+	v, err := toTree(value)
+	if err != nil {
+		return err
+	}
+	root, err := setWithRoot(d.root, path, v)
+	if err != nil {
+		return err
+	}
+	d.root = root
+	return nil
+}
+
+func setJSON(json, path, raw string, value interface{}) (string, error) {
+	steps, err := pathSteps(path)
+	if err != nil {
+		return "", err
+	}
+	if result, ok := setLazy(json, steps, raw, value); ok {
+		return result, nil
+	}
+	// 扫描不到时使用树，json 不合法或者路径的类型不对时返回错误
+	root, err := DecodeWithNumberMode(json, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	root, err = setStep(path, root, steps, value)
+	if err != nil {
+		return "", err
+	}
+	data, err := Marshal(root)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// setLazy splice raw to the path of json, the missing parents of the last existing value are created
+// from value. ok is false when the path can't be scanned, the tree should be used then.
+func setLazy(json string, steps []pathStep, raw string, value interface{}) (string, bool) {
+	i := skipSpace(json, 0)
+	for n, step := range steps {
+		if i >= len(json) {
+			return "", false
+		}
+		switch {
+		case json[i] == '{' && !step.isIndex:
+			m, count, end := scanObject(json, i, step.key)
+			if count > 0 {
+				i = m.value
+				continue
+			}
+			if end < 0 {
+				return "", false
+			}
+			child, ok := createRaw(steps[n+1:], raw, value)
+			if !ok {
+				return "", false
+			}
+			insert := quoteString(step.key) + ":" + child
+			p := lastValueEnd(json, i, end)
+			if p > i+1 {
+				insert = "," + insert
+			}
+			return json[:p] + insert + json[p:], true
+		case json[i] == '[' && step.isIndex:
+			index := step.index
+			if index < 0 {
+				_, _, count, end := scanArray(json, i, -1)
+				if end < 0 || index+count < 0 {
+					return "", false
+				}
+				index += count
+			}
+			m, found, count, end := scanArray(json, i, index)
+			if found {
+				i = m.value
+				continue
+			}
+			if end < 0 || index > count+maxSetPadding {
+				return "", false
+			}
+			child, ok := createRaw(steps[n+1:], raw, value)
+			if !ok {
+				return "", false
+			}
+			// 数组不够长时用 null 填充
+			insert := strings.Repeat("null,", index-count) + child
+			if count > 0 {
+				insert = "," + insert
+			}
+			p := lastValueEnd(json, i, end)
+			return json[:p] + insert + json[p:], true
+		case strings.HasPrefix(json[i:], "null"):
+			// null 替换为创建的值
+			end, ok := skipValue(json, i)
+			if !ok || json[i:end] != "null" {
+				return "", false
+			}
+			child, ok := createRaw(steps[n:], raw, value)
+			if !ok {
+				return "", false
+			}
+			return json[:i] + child + json[end:], true
+		default:
+			return "", false
+		}
+	}
+	end, ok := skipValue(json, i)
+	if !ok {
+		return "", false
+	}
+	return json[:i] + raw + json[end:], true
+}
+
+// lastValueEnd return the end of the last value in the object or array from json[start] to json[end],
+// the new member is inserted there.
+func lastValueEnd(json string, start, end int) int {
+	for end > start+1 && isWhitespace(json[end-1]) {
+		end--
+	}
+	return end
+}
+
+// createRaw create the value of the steps which don't exist and return its JSON.
+func createRaw(steps []pathStep, raw string, value interface{}) (string, bool) {
+	if len(steps) == 0 {
+		return raw, true
+	}
+	v, err := setStep("", nil, steps, value)
+	if err != nil {
+		return "", false
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// maxSetPadding is the max count of null which can be padded to an array by Set,
+// so that a large index doesn't allocate a huge array.
+const maxSetPadding = 1024
+
+func setWithRoot(root interface{}, path string, value interface{}) (interface{}, error) {
+	steps, err := pathSteps(path)
+	if err != nil {
+		return nil, err
+	}
+	return setStep(path, root, steps, value)
+}
+
+// pathStep is a key or an index of the path.
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s pathStep) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return s.key
+}
+
+// pathSteps parse the path which only contain keys and indexes, like a.b[0].c
+func pathSteps(path string) ([]pathStep, error) {
+	tokens, err := GrammarTokenize(path)
+	if err != nil {
+		return nil, newPathSyntaxError(path, "", err.Error())
+	}
+	var steps []pathStep
+	status := KeyStatus | BeginArrayIndexStatus
+	for _, token := range tokens {
+		switch token.T {
+		case Key:
+			bracket := includeGrammarTokenStatus(ArrayIndexStatus, status)
+			if !bracket && !includeGrammarTokenStatus(KeyStatus, status) {
+				return nil, newPathSyntaxError(path, token.Value, "unexpected key")
+			}
+			steps = append(steps, pathStep{key: token.Value})
+			status = DotStatus | BeginArrayIndexStatus
+			if bracket {
+				status = EndArrayIndexStatus
+			}
+		case ArrayIndex:
+			segment := "[" + token.Value + "]"
+			if !includeGrammarTokenStatus(ArrayIndexStatus, status) {
+				return nil, newPathSyntaxError(path, segment, "unexpected index")
+			}
+			index, err := strconv.Atoi(token.Value)
+			if err != nil {
+				return nil, newPathSyntaxError(path, segment, "only key and index are allowed")
+			}
+			steps = append(steps, pathStep{index: index, isIndex: true})
+			status = EndArrayIndexStatus
+		case BeginArrayIndex:
+			if !includeGrammarTokenStatus(BeginArrayIndexStatus, status) {
+				return nil, newPathSyntaxError(path, token.Value, "unexpected '['")
+			}
+			status = ArrayIndexStatus
+		case EndArrayIndex:
+			if !includeGrammarTokenStatus(EndArrayIndexStatus, status) {
+				return nil, newPathSyntaxError(path, token.Value, "unexpected ']'")
+			}
+			status = DotStatus | BeginArrayIndexStatus
+		case Dot:
+			if !includeGrammarTokenStatus(DotStatus, status) {
+				return nil, newPathSyntaxError(path, token.Value, "unexpected '.'")
+			}
+			status = KeyStatus
+		default:
+			return nil, newPathSyntaxError(path, token.Value, "only key and index are allowed")
+		}
+	}
+	if len(tokens) > 0 && !includeGrammarTokenStatus(DotStatus, status) {
+		return nil, newPathSyntaxError(path, "", "unexpected end of path")
+	}
+	return steps, nil
+}
+
+// setStep set value to the steps of node and return the new node, the missing node is created.
+func setStep(path string, node interface{}, steps []pathStep, value interface{}) (interface{}, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+	step := steps[0]
+	if _, ok := node.(NullValue); ok || node == nil {
+		// 不存在或者是 null 时创建
+		if step.isIndex {
+			arr := make([]interface{}, 0)
+			node = &arr
+		} else {
+			node = make(map[string]interface{})
+		}
+	}
+
+	if !step.isIndex {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, newPathError(path, step.String(), fmt.Sprintf("parent is %s, not JSONObject", typeOfToken(node)))
+		}
+		v, err := setStep(path, m[step.key], steps[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		m[step.key] = v
+		return m, nil
+	}
+
+	a, ok := node.(*[]interface{})
+	if !ok {
+		return nil, newPathError(path, step.String(), fmt.Sprintf("parent is %s, not ArrayObject", typeOfToken(node)))
+	}
+	index := step.index
+	if index < 0 {
+		index += len(*a)
+	}
+	if index < 0 || index > len(*a)+maxSetPadding {
+		return nil, newPathError(path, step.String(), fmt.Sprintf("index out of range with length %d", len(*a)))
+	}
+	var child interface{}
+	if index < len(*a) {
+		child = (*a)[index]
+	}
+	v, err := setStep(path, child, steps[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	// 数组不够长时用 null 填充
+	for len(*a) <= index {
+		*a = append(*a, JSONNull)
+	}
+	(*a)[index] = v
+	return a, nil
+}
+
+// Delete remove the object member or array element at the path and return the modified json,
+// the member or element is cut out of json with its comma, so the rest of json is kept as it is.
+// json is returned as it is when the path doesn't exist.
+func Delete(json, path string) (string, error) {
+	steps, err := pathSteps(path)
+	if err != nil {
+		return "", err
+	}
+	if len(steps) == 0 {
+		return "", newPathError(path, "", "can't delete the root")
+	}
+	if result, ok := deleteLazy(json, steps); ok {
+		return result, nil
+	}
+	root, err := DecodeWithNumberMode(json, NumberModeRaw)
+	if err != nil {
+		return "", err
+	}
+	if !deleteStep(root, steps) {
+		return json, nil
+	}
+	data, err := Marshal(root)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// deleteLazy cut the member or element at the path out of json, ok is false when the path can't be
+// scanned, the tree should be used then.
+func deleteLazy(json string, steps []pathStep) (string, bool) {
+	i := skipSpace(json, 0)
+	for n, step := range steps {
+		if i >= len(json) {
+			return "", false
+		}
+		var m member
+		switch {
+		case json[i] == '{' && !step.isIndex:
+			var (
+				count int
+				end   int
+			)
+			m, count, end = scanObject(json, i, step.key)
+			if end < 0 {
+				return "", false
+			}
+			if count == 0 {
+				return json, true
+			}
+			if count > 1 && n == len(steps)-1 {
+				// 重复的 key 要全部删除
+				return "", false
+			}
+		case json[i] == '[' && step.isIndex:
+			index := step.index
+			if index < 0 {
+				_, _, count, end := scanArray(json, i, -1)
+				if end < 0 {
+					return "", false
+				}
+				index += count
+			}
+			var (
+				found bool
+				end   int
+			)
+			m, found, _, end = scanArray(json, i, index)
+			if !found {
+				if end < 0 {
+					return "", false
+				}
+				return json, true
+			}
+		default:
+			if n == 0 {
+				// 根节点交给树检查
+				return "", false
+			}
+			// 父节点的类型不对时路径不存在
+			return json, true
+		}
+		if n < len(steps)-1 {
+			i = m.value
+			continue
+		}
+		switch {
+		case m.next >= 0:
+			return json[:m.start] + json[m.next:], true
+		case m.prev >= 0:
+			return json[:m.prev] + json[m.end:], true
+		default:
+			return json[:m.start] + json[m.end:], true
+		}
+	}
+	return "", false
+}
+
+// Delete remove the value at the path, like xjson.Delete.
+func (d *Document) Delete(path string) error {
+	steps, err := pathSteps(path)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return newPathError(path, "", "can't delete the root")
+	}
+	deleteStep(d.root, steps)
+	return nil
+}
+
+// deleteStep remove the last step from node and report whether it is removed,
+// it does nothing when the path doesn't exist.
+func deleteStep(node interface{}, steps []pathStep) bool {
+	step := steps[0]
+	switch data := node.(type) {
+	case map[string]interface{}:
+		if step.isIndex {
+			return false
+		}
+		v, ok := data[step.key]
+		if !ok {
+			return false
+		}
+		if len(steps) == 1 {
+			delete(data, step.key)
+			return true
+		}
+		return deleteStep(v, steps[1:])
+	case *[]interface{}:
+		if !step.isIndex {
+			return false
+		}
+		index := step.index
+		if index < 0 {
+			index += len(*data)
+		}
+		if index < 0 || index >= len(*data) {
+			return false
+		}
+		if len(steps) == 1 {
+			*data = append((*data)[:index], (*data)[index+1:]...)
+			return true
+		}
+		return deleteStep((*data)[index], steps[1:])
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package xjson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	str := `{"name":"bob","age":20,"price":1.50,"skill":{"lang":["go","java"]},"big":12345678901234567890}`
+	s, err := Set(str, "name", "alice")
+	assert.Nil(t, err)
+	fmt.Println(s)
+	assert.Equal(t, s, `{"name":"alice","age":20,"price":1.50,"skill":{"lang":["go","java"]},"big":12345678901234567890}`)
+
+	s, err = Set(str, "skill.lang[1]", "rust")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "skill.lang").String(), `["go","rust"]`)
+	s, err = Set(str, "skill.lang[-1]", "c")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "skill.lang[1]").String(), "c")
+
+	// 自动创建
+	s, err = Set(str, "address.city", "sz")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "address.city").String(), "sz")
+	s, err = Set(str, "skill.lang[4]", "c")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "skill.lang").String(), `["go","java",null,null,"c"]`)
+	s, err = Set(str, "list[1].name", "tom")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "list").String(), `[null,{"name":"tom"}]`)
+	s, err = Set(str, `["first-name"]`, "bob")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, `'first-name'`).String(), "bob")
+	s, err = Set(`{"a":null}`, "a.b", true)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"a":{"b":true}}`)
+	s, err = Set(`[]`, "[0]", map[string]interface{}{"a": []int{1}})
+	assert.Nil(t, err)
+	assert.Equal(t, s, `[{"a":[1]}]`)
+	s, err = Set(str, "", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `1`)
+
+	_, err = Set(str, "name.first", "bob")
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	_, err = Set(str, "skill.lang.a", "bob")
+	assert.NotNil(t, err)
+	_, err = Set(str, "skill.lang[-3]", "bob")
+	assert.NotNil(t, err)
+	_, err = Set(str, "skill.lang[*]", "bob")
+	assert.NotNil(t, err)
+	_, err = Set(str, "skill.", "bob")
+	assert.NotNil(t, err)
+	_, err = Set(str, "a", make(chan int))
+	assert.NotNil(t, err)
+	_, err = Set(`{`, "a", 1)
+	assert.NotNil(t, err)
+}
+
+func TestSetKeepFormat(t *testing.T) {
+	// 只替换目标，其他内容保持原样
+	str := `{
+  "z": 1,
+  "a": {"b": [1, 2], "c": {}},
+  "list": [ ]
+}`
+	cases := []struct {
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{"z", "x", "{\n  \"z\": \"x\",\n  \"a\": {\"b\": [1, 2], \"c\": {}},\n  \"list\": [ ]\n}"},
+		{"a.b[1]", 3, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 3], \"c\": {}},\n  \"list\": [ ]\n}"},
+		{"a.b[-1]", 3, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 3], \"c\": {}},\n  \"list\": [ ]\n}"},
+		{"a.b[3]", 3, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 2,null,3], \"c\": {}},\n  \"list\": [ ]\n}"},
+		{"a.c.d", true, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 2], \"c\": {\"d\":true}},\n  \"list\": [ ]\n}"},
+		{"list[0].e[1]", 1, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 2], \"c\": {}},\n  \"list\": [{\"e\":[null,1]} ]\n}"},
+		{"y", []int{1}, "{\n  \"z\": 1,\n  \"a\": {\"b\": [1, 2], \"c\": {}},\n  \"list\": [ ],\"y\":[1]\n}"},
+	}
+	for _, c := range cases {
+		s, err := Set(str, c.path, c.value)
+		assert.Nil(t, err, c.path)
+		assert.Equal(t, s, c.expected, c.path)
+	}
+
+	// 结构体的字段顺序保持不变
+	s, err := Set(`{"a":null}`, "a.p", Address{City: "cd"})
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"a":{"p":{"city":"cd"}}}`)
+	s, err = SetRaw(`{"a":1}`, "a", ` {"z": 1, "b": 2} `)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"a":{"z": 1, "b": 2}}`)
+}
+
+func TestSetLargeIndex(t *testing.T) {
+	// 过大的下标不能分配一个巨大的数组
+	_, err := Set(`{"a":[1]}`, "a[1000000000]", 1)
+	fmt.Println(err)
+	assert.NotNil(t, err)
+	_, err = Set(`{}`, "a[1025]", 1)
+	assert.NotNil(t, err)
+	s, err := Set(`{}`, "a[1024]", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "a.#").Int(), 1025)
+}
+
+func TestSetRaw(t *testing.T) {
+	s, err := SetRaw(`{"a":1}`, "b", `{"c":[1.10,null]}`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"a":1,"b":{"c":[1.10,null]}}`)
+	_, err = SetRaw(`{"a":1}`, "b", `{"c"`)
+	assert.NotNil(t, err)
+}
+
+func TestDocumentSet(t *testing.T) {
+	doc, err := ParseDocument(`{"a":{"b":1}}`)
+	assert.Nil(t, err)
+	assert.Nil(t, doc.Set("a.c[1]", "x"))
+	assert.Equal(t, doc.Get("a.c[1]").String(), "x")
+	assert.Equal(t, doc.String(), `{"a":{"b":1,"c":[null,"x"]}}`)
+	assert.NotNil(t, doc.Set("a.b.c", 1))
+}
+
+func TestDelete(t *testing.T) {
+	str := `{"person":{"name":"bob","email":"a@b.c"},"list":[1,2,3,4],"price":1.50}`
+	s, err := Delete(str, "person.email")
+	assert.Nil(t, err)
+	fmt.Println(s)
+	assert.Equal(t, s, `{"person":{"name":"bob"},"list":[1,2,3,4],"price":1.50}`)
+	s, err = Delete(str, "list[1]")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "list").String(), `[1,3,4]`)
+	s, err = Delete(str, "list[-1]")
+	assert.Nil(t, err)
+	assert.Equal(t, Get(s, "list").String(), `[1,2,3]`)
+	s, err = Delete(`[{"a":1,"b":2}]`, `[0]["a"]`)
+	assert.Nil(t, err)
+	assert.Equal(t, s, `[{"b":2}]`)
+
+	// 不存在时原样返回
+	for _, path := range []string{"person.phone", "person.name.first", "list[10]", "list.a", "person[0]", "a.b.c"} {
+		s, err = Delete(str, path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, s, str)
+	}
+	raw := `{ "z": 1,  "a": [ 1, 2 ], "b": {"c": 1} }`
+	for path, expected := range map[string]string{
+		"z":    `{ "a": [ 1, 2 ], "b": {"c": 1} }`,
+		"a[0]": `{ "z": 1,  "a": [ 2 ], "b": {"c": 1} }`,
+		"a[1]": `{ "z": 1,  "a": [ 1 ], "b": {"c": 1} }`,
+		"b.c":  `{ "z": 1,  "a": [ 1, 2 ], "b": {} }`,
+		"b":    `{ "z": 1,  "a": [ 1, 2 ] }`,
+	} {
+		s, err = Delete(raw, path)
+		assert.Nil(t, err, path)
+		assert.Equal(t, s, expected, path)
+	}
+	// 重复的 key 和树一样全部删除
+	s, err = Delete(`{"a":1,"b":2,"a":3}`, "a")
+	assert.Nil(t, err)
+	assert.Equal(t, s, `{"b":2}`)
+	s, err = Delete(raw, "a[5]")
+	assert.Nil(t, err)
+	assert.Equal(t, s, raw)
+
+	_, err = Delete(str, "")
+	assert.NotNil(t, err)
+	_, err = Delete(str, "list[*]")
+	assert.NotNil(t, err)
+	_, err = Delete(`{`, "a")
+	assert.NotNil(t, err)
+
+	doc, _ := ParseDocument(str)
+	assert.Nil(t, doc.Delete("person.email"))
+	assert.Equal(t, doc.Get("person.email").Exists(), false)
+	assert.NotNil(t, doc.Delete("person..email"))
+}
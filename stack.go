@@ -36,6 +36,7 @@ const (
 	Object    StackType = "object"
 	ObjectKey           = "objectKey"
 	Array               = "array"
+	Value               = "value"
 )
 
 type StackValue struct {
@@ -47,6 +48,11 @@ func NewObjectValue(object interface{}) *StackValue {
 	return &StackValue{stackType: Object, object: object}
 }
 
+// NewValue is the string/number/bool/null of the top level JSON.
+func NewValue(object interface{}) *StackValue {
+	return &StackValue{stackType: Value, object: object}
+}
+
 func NewObjectKey(key string) *StackValue {
 	return &StackValue{stackType: ObjectKey, object: key}
 }
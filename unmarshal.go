@@ -0,0 +1,309 @@
+package xjson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal a JSON description of themselves,
+// it is the same as json.Unmarshaler.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+var (
+	rawNumberType = reflect.TypeOf(RawNumber(""))
+	bigIntType    = reflect.TypeOf(big.Int{})
+	bigFloatType  = reflect.TypeOf(big.Float{})
+)
+
+// Unmarshal parse the JSON string and store the result in the value pointed to by v.
+func Unmarshal(data string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("unmarshal target must be a non-nil pointer")
+	}
+	// 使用 RawNumber 避免精度丢失
+	decode, err := DecodeWithNumberMode(data, NumberModeRaw)
+	if err != nil {
+		return err
+	}
+	return unmarshalValue(decode, rv.Elem())
+}
+
+func unmarshalValue(object interface{}, v reflect.Value) error {
+	if _, ok := object.(NullValue); ok {
+		// null 和 encoding/json 一样只清空 pointer/map/slice/interface
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	if u, tu := unmarshalers(v); u != nil {
+		b, err := Marshal(object)
+		if err != nil {
+			return err
+		}
+		return u.UnmarshalJSON(b)
+	} else if s, ok := object.(string); ok && tu != nil {
+		// TextUnmarshaler 只处理字符串，数字还是按 big.Float 等类型处理
+		return tu.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(object, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return unmarshalTypeError(object, v.Type())
+		}
+		if object == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		v.Set(reflect.ValueOf(covertObject(object)))
+		return nil
+	}
+
+	if token := typeOfToken(object); token == Number || token == Float {
+		// RawNumber/big.Int/big.Float 直接使用原始字面量
+		literal := interface2String(object)
+		switch v.Type() {
+		case rawNumberType:
+			v.SetString(literal)
+			return nil
+		case bigIntType:
+			i, err := RawNumber(literal).BigInt()
+			if err != nil {
+				return unmarshalTypeError(object, v.Type())
+			}
+			v.Set(reflect.ValueOf(*i))
+			return nil
+		case bigFloatType:
+			f, err := RawNumber(literal).BigFloat()
+			if err != nil {
+				return unmarshalTypeError(object, v.Type())
+			}
+			v.Set(reflect.ValueOf(*f))
+			return nil
+		}
+		if n, ok := object.(RawNumber); ok {
+			return unmarshalRawNumber(n, v)
+		}
+	}
+
+	switch data := object.(type) {
+	case map[string]interface{}:
+		switch v.Kind() {
+		case reflect.Struct:
+			return unmarshalStruct(data, v)
+		case reflect.Map:
+			return unmarshalMap(data, v)
+		}
+	case *[]interface{}:
+		switch v.Kind() {
+		case reflect.Slice:
+			slice := reflect.MakeSlice(v.Type(), len(*data), len(*data))
+			for i, e := range *data {
+				if err := unmarshalValue(e, slice.Index(i)); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		case reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				if i >= len(*data) {
+					v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+					continue
+				}
+				if err := unmarshalValue((*data)[i], v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(data)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte 是 base64 编码的字符串
+			b, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return fmt.Errorf("cannot unmarshal '%s' into Go value of type %s: %w", data, v.Type(), err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(data)
+			return nil
+		}
+	case int:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(int64(data)) {
+				return unmarshalTypeError(object, v.Type())
+			}
+			v.SetInt(int64(data))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if data < 0 || v.OverflowUint(uint64(data)) {
+				return unmarshalTypeError(object, v.Type())
+			}
+			v.SetUint(uint64(data))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(data))
+			return nil
+		}
+	case float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if v.OverflowFloat(data) {
+				return unmarshalTypeError(object, v.Type())
+			}
+			v.SetFloat(data)
+			return nil
+		}
+	}
+	return unmarshalTypeError(object, v.Type())
+}
+
+// unmarshalers return the Unmarshaler or TextUnmarshaler implemented by v or v.Addr().
+func unmarshalers(v reflect.Value) (Unmarshaler, encoding.TextUnmarshaler) {
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		v = v.Addr()
+	}
+	// nil pointer 先分配再检查它指向的值
+	if v.Kind() != reflect.Ptr || v.IsNil() || !v.CanInterface() {
+		return nil, nil
+	}
+	switch u := v.Interface().(type) {
+	case Unmarshaler:
+		return u, nil
+	case encoding.TextUnmarshaler:
+		return nil, u
+	}
+	return nil, nil
+}
+
+func unmarshalRawNumber(n RawNumber, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := n.Int64()
+		if err != nil || v.OverflowInt(i) {
+			return unmarshalTypeError(n, v.Type())
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, err := n.Uint64()
+		if err != nil || v.OverflowUint(i) {
+			return unmarshalTypeError(n, v.Type())
+		}
+		v.SetUint(i)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := n.Float64()
+		if err != nil || v.OverflowFloat(f) {
+			return unmarshalTypeError(n, v.Type())
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return unmarshalTypeError(n, v.Type())
+}
+
+func unmarshalStruct(data map[string]interface{}, v reflect.Value) error {
+	fields := cachedStructFields(v.Type())
+	for key, value := range data {
+		f, ok := findField(fields, key)
+		if !ok {
+			continue
+		}
+		fv, ok := fieldByIndex(v, f.index, true)
+		if !ok {
+			continue
+		}
+		if err := unmarshalValue(value, fv); err != nil {
+			return fmt.Errorf("field '%s': %w", key, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalMap(data map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(data)))
+	}
+	for key, value := range data {
+		kv := reflect.New(t.Key()).Elem()
+		switch kv.Kind() {
+		case reflect.String:
+			kv.SetString(key)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, err := strconv.ParseInt(key, 10, 64)
+			if err != nil || kv.OverflowInt(i) {
+				return unmarshalTypeError(key, t.Key())
+			}
+			kv.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			i, err := strconv.ParseUint(key, 10, 64)
+			if err != nil || kv.OverflowUint(i) {
+				return unmarshalTypeError(key, t.Key())
+			}
+			kv.SetUint(i)
+		default:
+			return fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+
+		ev := reflect.New(t.Elem()).Elem()
+		if err := unmarshalValue(value, ev); err != nil {
+			return err
+		}
+		v.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+// covertObject covert the decoded tree to the plain Go value, *[]interface{} become []interface{}
+// RawNumber become int or float64 and JSONNull become nil.
+func covertObject(object interface{}) interface{} {
+	switch data := object.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			m[k] = covertObject(v)
+		}
+		return m
+	case *[]interface{}:
+		s := make([]interface{}, len(*data))
+		for i, v := range *data {
+			s[i] = covertObject(v)
+		}
+		return s
+	case RawNumber:
+		return data.defaultValue()
+	case NullValue:
+		return nil
+	default:
+		return data
+	}
+}
+
+func unmarshalTypeError(object interface{}, t reflect.Type) error {
+	return fmt.Errorf("cannot unmarshal %s into Go value of type %s", typeOfToken(object), t)
+}
@@ -0,0 +1,167 @@
+package xjson
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Person struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Height  float64           `json:"height"`
+	Married bool              `json:"married"`
+	Skills  []string          `json:"skills"`
+	Address *Address          `json:"address"`
+	Tags    map[string]string `json:"tags"`
+	Ignore  string            `json:"-"`
+	Extra   interface{}       `json:"extra"`
+	NoTag   int
+}
+
+type Address struct {
+	City string `json:"city"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	str := `{"name":"bob","age":20,"height":1.8,"married":true,"skills":["go","java"],
+"address":{"city":"cd"},"tags":{"a":"b"},"Ignore":"x","extra":[1,{"a":"a"}],"notag":1}`
+	var p Person
+	err := Unmarshal(str, &p)
+	assert.Nil(t, err)
+	fmt.Println(p)
+	assert.Equal(t, p.Name, "bob")
+	assert.Equal(t, p.Age, 20)
+	assert.Equal(t, p.Height, 1.8)
+	assert.Equal(t, p.Married, true)
+	assert.Equal(t, p.Skills, []string{"go", "java"})
+	assert.Equal(t, p.Address.City, "cd")
+	assert.Equal(t, p.Tags["a"], "b")
+	assert.Equal(t, p.Ignore, "")
+	assert.Equal(t, p.Extra, []interface{}{1, map[string]interface{}{"a": "a"}})
+	assert.Equal(t, p.NoTag, 1)
+}
+
+func TestUnmarshal2(t *testing.T) {
+	var list []*Address
+	err := Unmarshal(`[{"city":"cd"},{"city":"bj"}]`, &list)
+	assert.Nil(t, err)
+	assert.Equal(t, len(list), 2)
+	assert.Equal(t, list[1].City, "bj")
+
+	var m map[int]float64
+	err = Unmarshal(`{"1":1,"2":2.5}`, &m)
+	assert.Nil(t, err)
+	assert.Equal(t, m[1], 1.0)
+	assert.Equal(t, m[2], 2.5)
+
+	var arr [2]int
+	err = Unmarshal(`[1,2,3]`, &arr)
+	assert.Nil(t, err)
+	assert.Equal(t, arr, [2]int{1, 2})
+}
+
+type Base struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type User struct {
+	*Base
+	Name string `json:"name"`
+}
+
+func TestUnmarshalEmbedded(t *testing.T) {
+	var u User
+	err := Unmarshal(`{"id":1,"name":"bob"}`, &u)
+	assert.Nil(t, err)
+	assert.Equal(t, u.ID, 1)
+	assert.Equal(t, u.Name, "bob")
+	assert.Equal(t, u.Base.Name, "")
+}
+
+type Node struct {
+	*Node
+	V int `json:"v"`
+}
+
+func TestUnmarshalSelfEmbedded(t *testing.T) {
+	var n Node
+	err := Unmarshal(`{"v":1}`, &n)
+	assert.Nil(t, err)
+	assert.Equal(t, n.V, 1)
+	assert.Nil(t, n.Node)
+
+	marshal, err := Marshal(n)
+	assert.Nil(t, err)
+	assert.Equal(t, string(marshal), `{"v":1}`)
+}
+
+type Level int
+
+func (l *Level) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"debug"`:
+		*l = 1
+	case `"info"`:
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", b)
+	}
+	return nil
+}
+
+func TestUnmarshalInterfaces(t *testing.T) {
+	type event struct {
+		Date  time.Time            `json:"date"`
+		Ptr   *time.Time           `json:"ptr"`
+		Data  []byte               `json:"data"`
+		Level Level                `json:"level"`
+		Raw   *big.Float           `json:"raw"`
+		Dates map[string]time.Time `json:"dates"`
+	}
+	date := time.Date(2022, 7, 1, 8, 0, 0, 0, time.UTC)
+	e := event{Date: date, Ptr: &date, Data: []byte("hi"), Raw: big.NewFloat(1.5), Dates: map[string]time.Time{"a": date}}
+	marshal, err := Marshal(e)
+	assert.Nil(t, err)
+	fmt.Println(string(marshal))
+
+	var e2 event
+	err = Unmarshal(strings.Replace(string(marshal), `"level":0`, `"level":"info"`, 1), &e2)
+	assert.Nil(t, err)
+	assert.True(t, e2.Date.Equal(date))
+	assert.True(t, e2.Ptr.Equal(date))
+	assert.Equal(t, e2.Data, []byte("hi"))
+	assert.Equal(t, e2.Level, Level(2))
+	assert.Equal(t, e2.Raw.String(), "1.5")
+	assert.True(t, e2.Dates["a"].Equal(date))
+
+	err = Unmarshal(`{"level":"x"}`, &e2)
+	assert.NotNil(t, err)
+	err = Unmarshal(`{"date":"x"}`, &e2)
+	assert.NotNil(t, err)
+	err = Unmarshal(`{"data":"!"}`, &e2)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+}
+
+func TestUnmarshalErr(t *testing.T) {
+	var p Person
+	err := Unmarshal(`{"name":1}`, &p)
+	assert.NotNil(t, err)
+	fmt.Println(err)
+
+	err = Unmarshal(`{"name":"bob"}`, p)
+	assert.NotNil(t, err)
+
+	var u8 uint8
+	err = Unmarshal(`[256]`, &[]uint8{u8})
+	assert.NotNil(t, err)
+	fmt.Println(err)
+
+	err = Unmarshal(`{"name":"bob"`, &p)
+	assert.NotNil(t, err)
+}